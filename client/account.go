@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrWrongCurrentPassword = errors.New("The current password is wrong")
+
+// Profile is the account of the logged in user.
+type Profile struct {
+	Username  string
+	CreatedAt time.Time
+	Stats     AccountStats
+}
+
+// AccountStats sums up the finished games of the user.
+type AccountStats struct {
+	GamesPlayed   int
+	GamesAsHider  int
+	GamesAsSeeker int
+	// longest time the user stayed hidden as hider
+	LongestHidingTime time.Duration
+}
+
+type changePasswordRequest struct {
+	OldPassword string
+	NewPassword string
+}
+
+type deleteAccountRequest struct {
+	Password string
+}
+
+func (a *API) GetProfile(ctx context.Context) (Profile, error) {
+	return doJSON[Profile](ctx, a, request{
+		method: "GET",
+		path:   "/profile",
+		action: "getting profile",
+	})
+}
+
+// ChangePassword sets a new password. The server ends all other sessions of
+// the user, the current one stays valid. It isn't replayed after logging in
+// again, the passwords aren't kept around for that.
+func (a *API) ChangePassword(ctx context.Context, oldPassword, newPassword string) error {
+	_, err := a.do(ctx, request{
+		method: "POST",
+		path:   "/password",
+		body: changePasswordRequest{
+			OldPassword: oldPassword,
+			NewPassword: newPassword,
+		},
+		noReplay: true,
+		action:   "changing password",
+		errors:   statusErrors{http.StatusBadRequest: ErrWrongCurrentPassword},
+	})
+	return err
+}
+
+// Logout revokes the current session on the server.
+func (a *API) Logout(ctx context.Context) error {
+	_, err := a.do(ctx, request{
+		method:   "POST",
+		path:     "/logout",
+		noReplay: true,
+		action:   "logging out",
+	})
+	return err
+}
+
+// DeleteAccount deletes the account of the user for good. The password is
+// asked again so a left open app can't be used to delete the account.
+func (a *API) DeleteAccount(ctx context.Context, password string) error {
+	_, err := a.do(ctx, request{
+		method:   "DELETE",
+		path:     "/account",
+		body:     deleteAccountRequest{Password: password},
+		noReplay: true,
+		action:   "deleting account",
+		errors:   statusErrors{http.StatusBadRequest: ErrWrongCurrentPassword},
+	})
+	return err
+}
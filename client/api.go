@@ -0,0 +1,343 @@
+// Package client talks to the fib-server HTTP API.
+//
+// It is a plain library without any dependency on fyne, so it can also be
+// used headless, e.g. by bots or scripted games. Errors are returned to the
+// caller, presenting them to the user is up to the widgets.
+package client
+
+import (
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/models"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors for the status codes the server uses across all endpoints.
+// Every APIError wraps one of these, so callers can check the outcome of a
+// request with errors.Is.
+var (
+	ErrBadRequest      = errors.New("Bad request")
+	ErrUnauthenticated = errors.New("Not logged in. Log out and back in.")
+	ErrForbidden       = errors.New("Not allowed")
+	ErrNotFound        = errors.New("Not found on the server")
+	ErrConflict        = errors.New("Conflicting request")
+)
+
+// ErrLobbyNotFound is reported by the lobby endpoints, see lobbyErrors.
+var ErrLobbyNotFound = errors.New("Lobby doesn't exist")
+
+var statusKinds = map[int]error{
+	http.StatusBadRequest:   ErrBadRequest,
+	http.StatusUnauthorized: ErrUnauthenticated,
+	http.StatusForbidden:    ErrForbidden,
+	http.StatusNotFound:     ErrNotFound,
+	http.StatusConflict:     ErrConflict,
+}
+
+// TokenSource provides the session and lobby token used to authorize requests.
+type TokenSource interface {
+	LoginInfo() (models.LoginInfo, error)
+}
+
+// StaticTokenSource always authorizes with the same login, e.g. to act for an
+// account that isn't the active one.
+type StaticTokenSource models.LoginInfo
+
+func (s StaticTokenSource) LoginInfo() (models.LoginInfo, error) {
+	return models.LoginInfo(s), nil
+}
+
+type dbTokenSource struct {
+	env env.Env
+}
+
+func (s dbTokenSource) LoginInfo() (models.LoginInfo, error) {
+	loginInfo, err := helpers.GetAppConfig(s.env)
+	if errors.Is(err, helpers.ErrNotLoggedIn) {
+		return models.LoginInfo{}, ErrUnauthenticated
+	}
+	return loginInfo, err
+}
+
+// API is a client for the fib-server HTTP API.
+type API struct {
+	httpClient *http.Client
+	baseUrl    string
+	tokens     TokenSource
+	db         *gorm.DB
+	retries    int
+	// called when the server rejects the session, see env.Reauthenticate
+	reauthenticate func(key string, replay func(ctx context.Context) error)
+}
+
+// APIOption configures the provided API client.
+type APIOption func(*API)
+
+// WithHTTPClient configures the API to use a custom http client.
+func WithHTTPClient(client *http.Client) APIOption {
+	return func(a *API) {
+		a.httpClient = client
+	}
+}
+
+// WithBaseUrl configures the API to talk to a different server.
+func WithBaseUrl(url string) APIOption {
+	return func(a *API) {
+		a.baseUrl = url
+	}
+}
+
+// WithRetries configures how often failed reads are retried before giving up.
+func WithRetries(retries int) APIOption {
+	return func(a *API) {
+		a.retries = retries
+	}
+}
+
+// WithTokenSource configures where the API gets its credentials from.
+func WithTokenSource(tokens TokenSource) APIOption {
+	return func(a *API) {
+		a.tokens = tokens
+	}
+}
+
+// WithReauthenticate configures what happens when the server rejects the
+// session. By default env.Reauthenticate is used.
+func WithReauthenticate(reauthenticate func(key string, replay func(ctx context.Context) error)) APIOption {
+	return func(a *API) {
+		a.reauthenticate = reauthenticate
+	}
+}
+
+// NewAPI creates an API client for the server configured in env.
+// By default the credentials are read from the LoginInfo in the database.
+func NewAPI(env env.Env, opts ...APIOption) *API {
+	a := &API{
+		httpClient: env.HTTPClient,
+		baseUrl:    env.Url,
+		tokens:     dbTokenSource{env: env},
+		db:         env.DB,
+		retries:    defaultRetries,
+
+		reauthenticate: env.Reauthenticate,
+	}
+	if a.httpClient == nil {
+		a.httpClient = http.DefaultClient
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// statusErrors maps HTTP status codes to the error an endpoint reports for them.
+type statusErrors map[int]error
+
+// lobbyErrors is used by the endpoints below /lobby/<token>, which answer
+// with a bad request if the lobby doesn't exist.
+var lobbyErrors = statusErrors{
+	http.StatusBadRequest: ErrLobbyNotFound,
+}
+
+type request struct {
+	method string
+	// path below the base url, or below /lobby/<token> if lobbyScoped is set
+	path        string
+	lobbyScoped bool
+	anonymous   bool
+	// queueable requests are stored and replayed later if the server can't be reached
+	queueable bool
+	// a queued request replaces the ones queued before to the same path,
+	// only the latest is sent
+	supersedes bool
+	// queued requests older than this are dropped instead of sent, zero
+	// keeps them until they are
+	maxQueueAge time.Duration
+	// requests that only make sense with the current session aren't replayed
+	// after the user logged in again
+	noReplay bool
+	body     any
+	// describes the request in error messages, e.g. "getting curses"
+	action string
+	errors statusErrors
+}
+
+// APIError is returned for every response with a status code outside of 2xx.
+// It wraps one of the ErrX kinds above and the endpoint specific error, if any.
+type APIError struct {
+	Status int
+	// method and path of the request, e.g. "GET /lobby/AG5L3T/curses"
+	Endpoint string
+	// message and code from the error payload, empty if the server didn't send one
+	Message string
+	Code    string
+
+	action string
+	kind   error
+	err    error
+}
+
+func (e *APIError) Error() string {
+	var message string
+	if e.err != nil {
+		message = e.err.Error()
+	} else {
+		message = e.action + " failed with http status code " + fmt.Sprint(e.Status)
+	}
+	if e.Message != "" && e.Message != message {
+		message += ": " + e.Message
+	}
+	return message
+}
+
+func (e *APIError) Unwrap() []error {
+	var errs []error
+	if e.kind != nil {
+		errs = append(errs, e.kind)
+	}
+	if e.err != nil {
+		errs = append(errs, e.err)
+	}
+	return errs
+}
+
+// errorPayload is the structured error body the server sends along with
+// a failed request.
+type errorPayload struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+// isMissingEndpoint reports whether err is the 404 of a route the server
+// doesn't know, which older servers answer with a plain text body. Newer
+// servers send an error payload with a code for their own 404s.
+func isMissingEndpoint(err error) bool {
+	var apiError *APIError
+	return errors.As(err, &apiError) && apiError.Status == http.StatusNotFound && apiError.Code == ""
+}
+
+// maxErrorMessageLength limits how much of a plain text error body ends up in
+// an APIError, so an html error page doesn't fill the whole dialog.
+const maxErrorMessageLength = 200
+
+func (r request) statusError(res *http.Response) error {
+	apiError := &APIError{
+		Status:   res.StatusCode,
+		Endpoint: res.Request.Method + " " + res.Request.URL.Path,
+		action:   r.action,
+		kind:     statusKinds[res.StatusCode],
+		err:      r.errors[res.StatusCode],
+	}
+
+	body, err := helpers.ReadHttpResponse(res.Body)
+	if err != nil || len(body) == 0 {
+		return apiError
+	}
+	var payload errorPayload
+	if json.Unmarshal(body, &payload) == nil {
+		apiError.Message = payload.Message
+		apiError.Code = payload.Code
+	} else {
+		message := strings.TrimSpace(string(body))
+		if len(message) > maxErrorMessageLength {
+			message = message[:maxErrorMessageLength] + "..."
+		}
+		apiError.Message = message
+	}
+	return apiError
+}
+
+func (a *API) do(ctx context.Context, r request) ([]byte, error) {
+	path := r.path
+	var token string
+	var loginInfo models.LoginInfo
+	if !r.anonymous {
+		var err error
+		loginInfo, err = a.tokens.LoginInfo()
+		if err != nil {
+			return nil, err
+		}
+		if sessionExpired(loginInfo) && !r.noReplay {
+			return nil, a.sessionRejected(r, ErrUnauthenticated)
+		}
+		if r.lobbyScoped {
+			path = "/lobby/" + loginInfo.LobbyToken + r.path
+		}
+		token = loginInfo.Token.String()
+	}
+
+	var body []byte
+	if r.body != nil {
+		marshalledBody, err := json.Marshal(r.body)
+		if err != nil {
+			return nil, err
+		}
+		body = marshalledBody
+	}
+
+	attempts := 1
+	if r.method == http.MethodGet {
+		attempts += a.retries
+	}
+	response, err := a.sendWithRetry(ctx, r, path, token, body, attempts)
+	if r.queueable && isConnectivityError(err) {
+		return nil, a.enqueue(r, path, body, loginInfo.ID)
+	}
+	if !r.anonymous && !r.noReplay && rejectsSession(r, err) {
+		return nil, a.sessionRejected(r, err)
+	}
+	return response, err
+}
+
+func (a *API) send(ctx context.Context, r request, path string, token string, body []byte) ([]byte, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, a.baseUrl+path, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+	res, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, r.statusError(res)
+	}
+	return helpers.ReadHttpResponse(res.Body)
+}
+
+func doJSON[T any](ctx context.Context, a *API, r request) (T, error) {
+	var response T
+	body, err := a.do(ctx, r)
+	if err != nil {
+		return response, err
+	}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+	return response, nil
+}
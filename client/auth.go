@@ -0,0 +1,59 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrUserExists      = errors.New("User already exists")
+	ErrWrongPassword   = errors.New("Wrong Password")
+	ErrUnknownUser     = errors.New("There is no user with this name. Register first.")
+	ErrAccountLocked   = errors.New("This account is locked after too many failed logins. Try again later.")
+	ErrLoginForbidden  = errors.New("This account isn't allowed to log in")
+	ErrInvalidUsername = errors.New("The server doesn't accept this username")
+)
+
+func (a *API) Register(ctx context.Context, username, password string) error {
+	_, err := a.do(ctx, request{
+		method:    "POST",
+		path:      "/register",
+		anonymous: true,
+		body: sharedModels.LoginInfo{
+			Username: username,
+			Password: password,
+		},
+		action: "registering",
+		errors: statusErrors{
+			http.StatusBadRequest:          ErrUserExists,
+			http.StatusConflict:            ErrUserExists,
+			http.StatusUnprocessableEntity: ErrInvalidUsername,
+		},
+	})
+	return err
+}
+
+func (a *API) Login(ctx context.Context, username, password string) (sharedModels.SessionToken, error) {
+	session, err := doJSON[sharedModels.SessionToken](ctx, a, request{
+		method:    "POST",
+		path:      "/login",
+		anonymous: true,
+		body: sharedModels.LoginInfo{
+			Username: username,
+			Password: password,
+		},
+		action: "logging in",
+		errors: statusErrors{
+			http.StatusBadRequest:   ErrWrongPassword,
+			http.StatusUnauthorized: ErrWrongPassword,
+			http.StatusForbidden:    ErrLoginForbidden,
+			http.StatusNotFound:     ErrUnknownUser,
+			http.StatusLocked:       ErrAccountLocked,
+		},
+	})
+	session.Expiry = a.ToLocal(session.Expiry)
+	return session, err
+}
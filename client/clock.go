@@ -0,0 +1,191 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// requests per clock sync, the median of their offsets is used
+	clockSamples = 7
+	// pause between the requests of a sync, so one slow moment of the
+	// network doesn't affect all of them
+	clockSamplePause = 200 * time.Millisecond
+)
+
+// ClockSample is one timestamped request to the server.
+type ClockSample struct {
+	// how far the server's clock is ahead of the device's
+	Offset    time.Duration
+	RoundTrip time.Duration
+	At        time.Time
+}
+
+// ClockEstimate is how far the server's clock is ahead of the device's. The
+// offset is the median of the samples, which drops the ones distorted by a
+// slow request in one direction.
+type ClockEstimate struct {
+	Offset time.Duration
+	// round trip of the sample the offset was taken from
+	RoundTrip time.Duration
+	Samples   []ClockSample
+	SyncedAt  time.Time
+}
+
+var (
+	clocksMu sync.RWMutex
+	// estimates by base url of the server
+	clocks = make(map[string]ClockEstimate)
+)
+
+// ServerTime returns the current time of the server.
+func (a *API) ServerTime(ctx context.Context) (time.Time, error) {
+	timeResponse, err := doJSON[sharedModels.TimeResponse](ctx, a, request{
+		method:    "GET",
+		path:      "/time",
+		anonymous: true,
+		action:    "getting server time",
+	})
+	return timeResponse.Time, err
+}
+
+// SyncClock estimates the offset of the server's clock the way NTP does: the
+// server's time is compared with the local time halfway through each request.
+// The estimate is kept for the server and used by ToLocal.
+func (a *API) SyncClock(ctx context.Context) (ClockEstimate, error) {
+	var samples []ClockSample
+	for i := 0; i < clockSamples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ClockEstimate{}, ctx.Err()
+			case <-time.After(clockSamplePause):
+			}
+		}
+		sent := time.Now()
+		serverTime, err := a.ServerTime(ctx)
+		if err != nil {
+			return ClockEstimate{}, err
+		}
+		received := time.Now()
+		roundTrip := received.Sub(sent)
+		samples = append(samples, ClockSample{
+			Offset:    serverTime.Sub(sent.Add(roundTrip / 2)),
+			RoundTrip: roundTrip,
+			At:        received,
+		})
+	}
+
+	sorted := slices.Clone(samples)
+	slices.SortFunc(sorted, func(a, b ClockSample) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+	median := sorted[len(sorted)/2]
+	estimate := ClockEstimate{
+		Offset:    median.Offset,
+		RoundTrip: median.RoundTrip,
+		Samples:   samples,
+		SyncedAt:  time.Now(),
+	}
+
+	clocksMu.Lock()
+	clocks[a.baseUrl] = estimate
+	clocksMu.Unlock()
+	return estimate, nil
+}
+
+// ClockEstimate returns the last estimate for the server, ok is false if the
+// clock wasn't synced yet.
+func (a *API) ClockEstimate() (estimate ClockEstimate, ok bool) {
+	clocksMu.RLock()
+	defer clocksMu.RUnlock()
+	estimate, ok = clocks[a.baseUrl]
+	return estimate, ok
+}
+
+// ToLocal converts a timestamp of the server to the device's clock. Until the
+// clock is synced the timestamp is returned as is.
+func (a *API) ToLocal(serverTime time.Time) time.Time {
+	if serverTime.IsZero() {
+		return serverTime
+	}
+	estimate, _ := a.ClockEstimate()
+	return serverTime.Add(-estimate.Offset)
+}
+
+// localizable is wire data with timestamps of the server.
+type localizable[T any] interface {
+	toLocal(a *API) T
+}
+
+// eventLocalizers convert the timestamps in the data of pushed events. Polled
+// events come from the API methods, which already converted them.
+var eventLocalizers = map[EventType]func(a *API, data json.RawMessage) (json.RawMessage, error){
+	EventHistory: localizeJSON[History],
+	EventLobby:   localizeJSON[LobbyDetails],
+	EventTeam:    localizeJSON[SeekerTeam],
+}
+
+func localizeJSON[T localizable[T]](a *API, data json.RawMessage) (json.RawMessage, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value.toLocal(a))
+}
+
+// localizeEvent converts the timestamps in the data of a pushed event to the
+// device's clock.
+func (a *API) localizeEvent(event Event) (Event, error) {
+	localize, ok := eventLocalizers[event.Type]
+	if !ok {
+		return event, nil
+	}
+	data, err := localize(a, event.Data)
+	if err != nil {
+		return event, err
+	}
+	event.Data = data
+	return event, nil
+}
+
+func (h History) toLocal(a *API) History {
+	local := slices.Clone(h)
+	for i := range local {
+		local[i].AskedAt = a.ToLocal(local[i].AskedAt)
+	}
+	return local
+}
+
+func (d LobbyDetails) toLocal(a *API) LobbyDetails {
+	d.CreatedAt = a.ToLocal(d.CreatedAt)
+	if d.StartsAt != nil {
+		startsAt := a.ToLocal(*d.StartsAt)
+		d.StartsAt = &startsAt
+	}
+	d.Players = slices.Clone(d.Players)
+	for i := range d.Players {
+		d.Players[i].LastSeen = a.ToLocal(d.Players[i].LastSeen)
+	}
+	return d
+}
+
+func (t SeekerTeam) toLocal(a *API) SeekerTeam {
+	t.Seekers = slices.Clone(t.Seekers)
+	for i := range t.Seekers {
+		t.Seekers[i].LocatedAt = a.ToLocal(t.Seekers[i].LocatedAt)
+	}
+	cooldowns := make(map[QuestionCategory]time.Time, len(t.Cooldowns))
+	for category, until := range t.Cooldowns {
+		cooldowns[category] = a.ToLocal(until)
+	}
+	t.Cooldowns = cooldowns
+	return t
+}
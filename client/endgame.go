@@ -0,0 +1,47 @@
+package client
+
+import (
+	"github.com/paulmach/orb/geojson"
+
+	"context"
+	"errors"
+	"net/http"
+)
+
+var ErrNotInHidingZone = errors.New("You need to be inside the hiding zone to see it")
+
+// GetHidingZone returns the circle around the hiding zone. The server only
+// reveals it to seekers whose saved location is inside it.
+func (a *API) GetHidingZone(ctx context.Context) (*geojson.FeatureCollection, error) {
+	fc, err := doJSON[geojson.FeatureCollection](ctx, a, request{
+		method:      "GET",
+		path:        "/hidingZone",
+		lobbyScoped: true,
+		action:      "getting hiding zone",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusForbidden:  ErrNotSeeker,
+			http.StatusConflict:   ErrNotInHidingZone,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fc, nil
+}
+
+// ConfirmFound is sent by the hider once the seekers found them, which ends
+// the game.
+func (a *API) ConfirmFound(ctx context.Context) error {
+	_, err := a.do(ctx, request{
+		method:      "POST",
+		path:        "/found",
+		lobbyScoped: true,
+		action:      "confirming that the hider was found",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusForbidden:  ErrNotHider,
+		},
+	})
+	return err
+}
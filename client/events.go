@@ -0,0 +1,276 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-client/workers"
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventType is the part of the lobby state an Event is about.
+type EventType string
+
+// Every event carries the full current value of its part of the lobby state,
+// so a subscriber never has to fetch anything after receiving one.
+const (
+	// data is a sharedModels.PhaseResponse
+	EventPhase EventType = "phase"
+	// data is a sharedModels.ReadinessResponse
+	EventReadiness EventType = "readiness"
+	// data is a History
+	EventHistory EventType = "history"
+	// data is a sharedModels.CardList
+	EventCurses EventType = "curses"
+	// data is a sharedModels.CardList
+	EventHand EventType = "hand"
+	// data is a SeekerTeam
+	EventTeam EventType = "team"
+	// data is the LobbyDetails as the user sees them, without players once
+	// the user isn't a member of the lobby anymore
+	EventLobby EventType = "lobby"
+	// type of pushed events without an event field, as in the SSE spec
+	EventMessage EventType = "message"
+)
+
+// Event is a change of the lobby state, either pushed by the server or
+// detected by polling.
+type Event struct {
+	ID   string
+	Type EventType
+	Data json.RawMessage
+}
+
+// Decode unmarshals the data of the event into v.
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+const (
+	subscriberBufferSize = 16
+	pollInterval         = 2 * time.Second
+	minStreamRetryDelay  = 2 * time.Second
+	maxStreamRetryDelay  = time.Minute
+)
+
+type subscriber struct {
+	events chan Event
+	types  map[EventType]bool
+}
+
+// eventStream is the single connection to the event stream of one lobby,
+// shared by all subscribers.
+type eventStream struct {
+	api *API
+	key string
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]bool
+	// last payload of every event type, used to drop events that don't change anything
+	last        map[EventType][]byte
+	lastEventID string
+	cancel      context.CancelFunc
+}
+
+var (
+	streamsMu sync.Mutex
+	streams   = make(map[string]*eventStream)
+)
+
+// Subscribe returns a channel receiving the events of the given types for the
+// lobby the user is currently in. All subscribers of a lobby share one
+// connection, which is closed once the last subscriber's ctx is done. The
+// channel is closed when ctx is done.
+func (a *API) Subscribe(ctx context.Context, types ...EventType) (<-chan Event, error) {
+	loginInfo, err := a.tokens.LoginInfo()
+	if err != nil {
+		return nil, err
+	}
+	key := a.baseUrl + "/lobby/" + loginInfo.LobbyToken
+
+	sub := &subscriber{
+		events: make(chan Event, subscriberBufferSize),
+		types:  make(map[EventType]bool),
+	}
+	for _, eventType := range types {
+		sub.types[eventType] = true
+	}
+
+	streamsMu.Lock()
+	stream, ok := streams[key]
+	if !ok {
+		stream = &eventStream{
+			api:         a,
+			key:         key,
+			subscribers: make(map[*subscriber]bool),
+			last:        make(map[EventType][]byte),
+		}
+		streamCtx, cancel := context.WithCancel(context.Background())
+		stream.cancel = cancel
+		streams[key] = stream
+		workers.Go(streamCtx, "lobby event stream", stream.run)
+	}
+	stream.mu.Lock()
+	stream.subscribers[sub] = true
+	stream.mu.Unlock()
+	streamsMu.Unlock()
+
+	workers.Go(ctx, "lobby event subscription", func(ctx context.Context) {
+		<-ctx.Done()
+		stream.unsubscribe(sub)
+	})
+
+	return sub.events, nil
+}
+
+func (s *eventStream) unsubscribe(sub *subscriber) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscribers, sub)
+	close(sub.events)
+	if len(s.subscribers) == 0 {
+		s.cancel()
+		delete(streams, s.key)
+	}
+}
+
+// dispatch hands the event to all subscribers of its type, unless it doesn't
+// change anything compared to the last event of that type.
+func (s *eventStream) dispatch(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.ID != "" {
+		s.lastEventID = event.ID
+	}
+	if bytes.Equal(s.last[event.Type], event.Data) {
+		return
+	}
+	s.last[event.Type] = event.Data
+
+	for sub := range s.subscribers {
+		if !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			log.Warn().Msg("dropping " + string(event.Type) + " event for slow subscriber")
+		}
+	}
+}
+
+// run listens to the server's event stream and falls back to polling while
+// the stream can't be used, trying to reconnect with increasing delay.
+func (s *eventStream) run(ctx context.Context) {
+	retryDelay := minStreamRetryDelay
+	for {
+		connected, err := s.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			retryDelay = minStreamRetryDelay
+		}
+		log.Debug().Msg("lobby event stream unavailable, polling for " + fmt.Sprint(retryDelay) + ": " + fmt.Sprint(err))
+
+		s.poll(ctx, time.Now().Add(retryDelay))
+		if ctx.Err() != nil {
+			return
+		}
+		retryDelay = min(retryDelay*2, maxStreamRetryDelay)
+	}
+}
+
+// poll fetches the lobby state every pollInterval until the deadline and
+// dispatches the parts that changed.
+func (s *eventStream) poll(ctx context.Context, until time.Time) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		s.pollOnce(ctx)
+		if time.Now().After(until) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *eventStream) pollOnce(ctx context.Context) {
+	a := s.api
+	pollers := map[EventType]func() (any, error){
+		EventPhase: func() (any, error) {
+			phase, err := a.GetGamePhase(ctx)
+			return sharedModels.PhaseResponse{Phase: phase}, err
+		},
+		EventReadiness: func() (any, error) {
+			ready, err := a.IsLobbyComplete(ctx)
+			return sharedModels.ReadinessResponse{Ready: ready}, err
+		},
+		EventHistory: func() (any, error) {
+			return a.GetHistory(ctx)
+		},
+		EventCurses: func() (any, error) {
+			curses, err := a.GetCurses(ctx)
+			return sharedModels.CardList{List: curses}, err
+		},
+		EventHand: func() (any, error) {
+			return a.GetHiderHand(ctx)
+		},
+		EventTeam: func() (any, error) {
+			return a.GetSeekerTeam(ctx)
+		},
+		EventLobby: func() (any, error) {
+			loginInfo, err := a.tokens.LoginInfo()
+			if err != nil {
+				return nil, err
+			}
+			details, err := a.GetLobbyDetails(ctx, loginInfo.LobbyToken)
+			if errors.Is(err, ErrNotInLobby) {
+				return LobbyDetails{LobbyToken: loginInfo.LobbyToken}, nil
+			}
+			return details, err
+		},
+	}
+
+	for eventType, poller := range pollers {
+		if !s.hasSubscribers(eventType) {
+			continue
+		}
+		value, err := poller()
+		if err != nil {
+			log.Debug().Msg("polling " + string(eventType) + " failed: " + fmt.Sprint(err))
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		s.dispatch(Event{Type: eventType, Data: data})
+	}
+}
+
+func (s *eventStream) hasSubscribers(eventType EventType) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if sub.types[eventType] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrRoleTaken    = errors.New("This role has already been selected")
+	ErrNotInLobby   = errors.New("You are not a member of this lobby")
+	ErrGameStarted  = errors.New("The game has already started, roles can't be changed anymore")
+	ErrNotHost      = errors.New("Only the host of the lobby can do this")
+	ErrNoSuchPlayer = errors.New("This player is not in the lobby")
+)
+
+// roleChangeErrors is used by the endpoints changing roles, which only work
+// before the game starts.
+var roleChangeErrors = statusErrors{
+	http.StatusBadRequest: ErrLobbyNotFound,
+	http.StatusConflict:   ErrRoleTaken,
+	http.StatusLocked:     ErrGameStarted,
+}
+
+// hostErrors is used by the endpoints only the host of a lobby may call.
+var hostErrors = statusErrors{
+	http.StatusBadRequest: ErrLobbyNotFound,
+	http.StatusForbidden:  ErrNotHost,
+	http.StatusNotFound:   ErrNoSuchPlayer,
+	http.StatusConflict:   ErrRoleTaken,
+	http.StatusLocked:     ErrGameStarted,
+}
+
+// CreateLobby creates a lobby with the given rules.
+func (a *API) CreateLobby(ctx context.Context, settings LobbySettings) (sharedModels.LobbyCreationResponse, error) {
+	err := settings.Validate()
+	if err != nil {
+		return sharedModels.LobbyCreationResponse{}, err
+	}
+	return doJSON[sharedModels.LobbyCreationResponse](ctx, a, request{
+		method: "POST",
+		path:   "/lobby/create",
+		body:   settings,
+		action: "creating lobby",
+		errors: statusErrors{http.StatusForbidden: ErrUnauthenticated},
+	})
+}
+
+func (a *API) JoinLobby(ctx context.Context, lobbyToken string) (sharedModels.JoinResponse, error) {
+	return doJSON[sharedModels.JoinResponse](ctx, a, request{
+		method: "POST",
+		path:   "/lobby/join",
+		body:   sharedModels.LobbyJoinRequest{LobbyToken: lobbyToken},
+		action: "joining lobby",
+		errors: statusErrors{http.StatusForbidden: ErrUnauthenticated},
+	})
+}
+
+func (a *API) GetRoles(ctx context.Context, lobbyToken string) (sharedModels.RoleAvailability, error) {
+	return doJSON[sharedModels.RoleAvailability](ctx, a, request{
+		method: "GET",
+		path:   "/lobby/" + lobbyToken + "/roles",
+		action: "getting available roles",
+		errors: statusErrors{http.StatusForbidden: ErrUnauthenticated},
+	})
+}
+
+func (a *API) SelectRole(ctx context.Context, lobbyToken string, role sharedModels.UserRole) error {
+	_, err := a.do(ctx, request{
+		method: "POST",
+		path:   "/lobby/" + lobbyToken + "/selectRole",
+		body:   sharedModels.UserRoleRequest{Role: role},
+		action: "selecting role",
+		errors: statusErrors{http.StatusConflict: ErrRoleTaken},
+	})
+	return err
+}
+
+// ChangeRole switches the role of the user before the game starts. NoRole
+// releases the current role, so another player can take it.
+func (a *API) ChangeRole(ctx context.Context, lobbyToken string, role sharedModels.UserRole) error {
+	_, err := a.do(ctx, request{
+		method: "POST",
+		path:   "/lobby/" + lobbyToken + "/changeRole",
+		body:   sharedModels.UserRoleRequest{Role: role},
+		action: "changing role",
+		errors: roleChangeErrors,
+	})
+	return err
+}
+
+// LeaveLobby tells the server the user left the lobby, releasing their role.
+// It is sent later if the server can't be reached. Servers that don't know
+// about leaving keep the user in the lobby, that isn't treated as an error.
+func (a *API) LeaveLobby(ctx context.Context, lobbyToken string) error {
+	_, err := a.do(ctx, request{
+		method:    "POST",
+		path:      "/lobby/" + lobbyToken + "/leave",
+		queueable: true,
+		action:    "leaving lobby",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusForbidden:  ErrNotInLobby,
+		},
+	})
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// PlayerRequest names a player of the lobby.
+type PlayerRequest struct {
+	Username string
+}
+
+// RoleAssignment gives a player of the lobby a role.
+type RoleAssignment struct {
+	Username string
+	Role     sharedModels.UserRole
+}
+
+// KickPlayer removes a player from the lobby before the game starts. Only the
+// host may do this.
+func (a *API) KickPlayer(ctx context.Context, lobbyToken string, username string) error {
+	_, err := a.do(ctx, request{
+		method: "POST",
+		path:   "/lobby/" + lobbyToken + "/kick",
+		body:   PlayerRequest{Username: username},
+		action: "removing " + username + " from the lobby",
+		errors: hostErrors,
+	})
+	return err
+}
+
+// AssignRole gives a player another role before the game starts. Only the
+// host may do this.
+func (a *API) AssignRole(ctx context.Context, lobbyToken string, username string, role sharedModels.UserRole) error {
+	_, err := a.do(ctx, request{
+		method: "POST",
+		path:   "/lobby/" + lobbyToken + "/assignRole",
+		body:   RoleAssignment{Username: username, Role: role},
+		action: "assigning a role to " + username,
+		errors: hostErrors,
+	})
+	return err
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
+	"net/http"
+	"time"
+)
+
+// LobbyDetails describes a lobby and everyone in it.
+type LobbyDetails struct {
+	LobbyToken string
+	CreatedAt  time.Time
+	Phase      sharedModels.GamePhase
+	// username of the player who created the lobby
+	Host    string
+	Players []Player
+	// set once every player is ready, the game starts then unless someone
+	// cancels their readiness before
+	StartsAt *time.Time
+}
+
+// Player is a member of a lobby.
+type Player struct {
+	Username string
+	Role     sharedModels.UserRole
+	Ready    bool
+	// last time the player's app talked to the server
+	LastSeen time.Time
+}
+
+// Player returns the member of the lobby with the username.
+func (d LobbyDetails) Player(username string) (Player, bool) {
+	for _, player := range d.Players {
+		if player.Username == username {
+			return player, true
+		}
+	}
+	return Player{}, false
+}
+
+// ReadyCount returns how many players are ready.
+func (d LobbyDetails) ReadyCount() int {
+	count := 0
+	for _, player := range d.Players {
+		if player.Ready {
+			count++
+		}
+	}
+	return count
+}
+
+// LobbySummary is a lobby the user is a member of, as listed by /lobbies.
+type LobbySummary struct {
+	LobbyToken string
+	CreatedAt  time.Time
+	Phase      sharedModels.GamePhase
+	Role       sharedModels.UserRole
+}
+
+// GetLobbyDetails returns the players of the lobby with their roles and
+// readiness. Only members of the lobby may see it.
+func (a *API) GetLobbyDetails(ctx context.Context, lobbyToken string) (LobbyDetails, error) {
+	details, err := doJSON[LobbyDetails](ctx, a, request{
+		method: "GET",
+		path:   "/lobby/" + lobbyToken + "/details",
+		action: "getting lobby details",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusForbidden:  ErrNotInLobby,
+		},
+	})
+	return details.toLocal(a), err
+}
+
+// GetMyLobbies returns all lobbies the user is a member of, on any device.
+func (a *API) GetMyLobbies(ctx context.Context) ([]LobbySummary, error) {
+	return doJSON[[]LobbySummary](ctx, a, request{
+		method: "GET",
+		path:   "/lobbies",
+		action: "getting your lobbies",
+	})
+}
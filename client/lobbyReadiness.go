@@ -1,80 +1,41 @@
 package client
 
 import (
-	"github.com/jkulzer/fib-client/env"
-	"github.com/jkulzer/fib-client/helpers"
-
 	"github.com/jkulzer/fib-server/sharedModels"
 
-	"bytes"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/http"
-
-	"fyne.io/fyne/v2"
+	"context"
+	"time"
 )
 
-func IsLobbyComplete(env env.Env, parentWindow fyne.Window) (bool, error) {
-	loginInfo, err := helpers.GetAppConfig(env, parentWindow)
-	if err != nil {
-		return false, err
-	}
-	req, err := http.NewRequest("GET", env.Url+"/lobby/"+loginInfo.LobbyToken+"/readiness", nil)
+func (a *API) IsLobbyComplete(ctx context.Context) (bool, error) {
+	readinessResponse, err := doJSON[sharedModels.ReadinessResponse](ctx, a, request{
+		method:      "GET",
+		path:        "/readiness",
+		lobbyScoped: true,
+		action:      "readiness request",
+	})
 	if err != nil {
 		return false, err
 	}
-	req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return false, err
-	}
-	switch res.StatusCode {
-	case http.StatusOK:
-		var responseStruct sharedModels.ReadinessResponse
-		responseBytes, err := helpers.ReadHttpResponse(res.Body)
-		if err != nil {
-			return false, err
-		}
-		err = json.Unmarshal(responseBytes, &responseStruct)
-		if err != nil {
-			return false, err
-		} else {
-			return responseStruct.Ready, nil
-		}
-	default:
-		return false, errors.New("readyiness request failed with http status code " + fmt.Sprint(res.StatusCode))
-	}
+	return readinessResponse.Ready, nil
 }
 
-func SetReadiness(env env.Env, parentWindow fyne.Window, ready bool) error {
-	loginInfo, err := helpers.GetAppConfig(env, parentWindow)
-	if err != nil {
-		return err
-	}
-
-	setReadinessStruct := sharedModels.SetReadinessRequest{
-		Ready: ready,
-	}
-	marshalledJson, err := json.Marshal(setReadinessStruct)
-	if err != nil {
-		return err
-	}
-	marshalledJsonReader := bytes.NewReader(marshalledJson)
-
-	req, err := http.NewRequest("PUT", env.Url+"/lobby/"+loginInfo.LobbyToken+"/readiness", marshalledJsonReader)
-	if err != nil {
-		return err
-	}
-	req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	switch res.StatusCode {
-	case http.StatusOK:
-		return nil
-	default:
-		return errors.New("readiness setting failed with http status code " + fmt.Sprint(res.StatusCode))
-	}
+// readinessQueueAge is how long a readiness change made offline is kept. Sent
+// later it could stop a start countdown that is long over.
+const readinessQueueAge = 2 * time.Minute
+
+// SetReadiness is queued while offline. Only the latest readiness is sent and
+// only if it is recent.
+func (a *API) SetReadiness(ctx context.Context, ready bool) error {
+	_, err := a.do(ctx, request{
+		method:      "PUT",
+		path:        "/readiness",
+		lobbyScoped: true,
+		queueable:   true,
+		supersedes:  true,
+		maxQueueAge: readinessQueueAge,
+		body:        sharedModels.SetReadinessRequest{Ready: ready},
+		action:      "readiness setting",
+	})
+	return err
 }
@@ -1,93 +1,48 @@
 package client
 
 import (
-	"github.com/jkulzer/fib-client/env"
-	"github.com/jkulzer/fib-client/helpers"
-
 	"github.com/jkulzer/fib-server/sharedModels"
 
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/paulmach/orb"
-
-	"fyne.io/fyne/v2"
 )
 
-func ValidateAndSetHidingZone(env env.Env, parentWindow fyne.Window, point orb.Point) error {
-	loginInfo, err := helpers.GetAppConfig(env, parentWindow)
-	if err != nil {
-		return err
-	}
-
-	locationRequest := sharedModels.LocationRequest{
-		Location: point,
-	}
-	marshalledJson, err := json.Marshal(locationRequest)
-	if err != nil {
-		return err
-	}
-	marshalledJsonReader := bytes.NewReader(marshalledJson)
+var (
+	ErrInvalidHidingZone = errors.New("Invalid Hiding Spot.\nProbably not close enough to a train station (has to be 500 meters) or not in Berlin. Move closer to a train station in Berlin and try again")
+	ErrNotHider          = errors.New("You are not a hider and therefore cannot set your hiding spot.")
+)
 
-	req, err := http.NewRequest("PUT", env.Url+"/lobby/"+loginInfo.LobbyToken+"/saveHidingZone", marshalledJsonReader)
-	if err != nil {
-		return err
-	}
-	req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	switch res.StatusCode {
-	case http.StatusOK:
-		return nil
-	case http.StatusBadRequest:
-		return errors.New("Invalid Hiding Spot.\nProbably not close enough to a train station (has to be 500 meters) or not in Berlin. Move closer to a train station in Berlin and try again")
-	case http.StatusForbidden:
-		return errors.New("You are not a hider and therefore cannot set your hiding spot.")
-	default:
-		return errors.New("setting hiding spot failed with http status code " + fmt.Sprint(res.StatusCode))
-	}
+func (a *API) ValidateAndSetHidingZone(ctx context.Context, point orb.Point) error {
+	_, err := a.do(ctx, request{
+		method:      "PUT",
+		path:        "/saveHidingZone",
+		lobbyScoped: true,
+		body:        sharedModels.LocationRequest{Location: point},
+		action:      "setting hiding spot",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrInvalidHidingZone,
+			http.StatusForbidden:  ErrNotHider,
+		},
+	})
+	return err
 }
 
-func SaveLocation(env env.Env, parentWindow fyne.Window, point orb.Point) error {
-	loginInfo, err := helpers.GetAppConfig(env, parentWindow)
-	if err != nil {
-		return err
-	}
-
-	locationRequest := sharedModels.LocationRequest{
-		Location: point,
-	}
-	marshalledJson, err := json.Marshal(locationRequest)
-	if err != nil {
-		return err
-	}
-	marshalledJsonReader := bytes.NewReader(marshalledJson)
-
-	req, err := http.NewRequest("PUT", env.Url+"/lobby/"+loginInfo.LobbyToken+"/saveLocation", marshalledJsonReader)
-	if err != nil {
-		return err
-	}
-	req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-
-	switch res.StatusCode {
-	case http.StatusOK:
-		return nil
-	case http.StatusBadRequest:
-		return errors.New("Can't find lobby")
-	case http.StatusForbidden:
-		return errors.New("Not authenticated.")
-	case http.StatusConflict:
-		return sharedModels.ErrHiderLocationNotInZone
-	default:
-		return errors.New("saving location failed with http status code " + fmt.Sprint(res.StatusCode))
-	}
+func (a *API) SaveLocation(ctx context.Context, point orb.Point) error {
+	_, err := a.do(ctx, request{
+		method:      "PUT",
+		path:        "/saveLocation",
+		lobbyScoped: true,
+		queueable:   true,
+		body:        sharedModels.LocationRequest{Location: point},
+		action:      "saving location",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusForbidden:  ErrUnauthenticated,
+			http.StatusConflict:   sharedModels.ErrHiderLocationNotInZone,
+		},
+	})
+	return err
 }
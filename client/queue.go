@@ -0,0 +1,122 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-client/models"
+
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrQueued is returned by actions that couldn't reach the server. They are
+// stored in the database and sent once the connection is back.
+var ErrQueued = errors.New("No connection to the server. The action was saved and will be sent once you are back online.")
+
+const queueDrainInterval = 5 * time.Second
+
+func (a *API) enqueue(r request, path string, body []byte, loginInfoID uint) error {
+	pendingAction := models.PendingAction{
+		Method:      r.method,
+		Path:        path,
+		Body:        body,
+		Action:      r.action,
+		ServerUrl:   a.baseUrl,
+		LoginInfoID: loginInfoID,
+	}
+	if r.maxQueueAge > 0 {
+		pendingAction.ExpiresAt = time.Now().Add(r.maxQueueAge)
+	}
+	if r.supersedes {
+		result := a.db.Unscoped().Where("server_url = ? AND login_info_id = ? AND method = ? AND path = ?", a.baseUrl, loginInfoID, r.method, path).Delete(&models.PendingAction{})
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	result := a.db.Create(&pendingAction)
+	if result.Error != nil {
+		return result.Error
+	}
+	log.Info().Msg("queued " + r.action + " until the server is reachable again")
+	return ErrQueued
+}
+
+// PendingActions returns the number of queued actions of the active account
+// that weren't sent yet.
+func (a *API) PendingActions() (int64, error) {
+	loginInfo, err := a.tokens.LoginInfo()
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	result := a.db.Model(&models.PendingAction{}).Where("server_url = ? AND login_info_id = ?", a.baseUrl, loginInfo.ID).Count(&count)
+	return count, result.Error
+}
+
+// DrainQueue sends the queued actions of the active account in the order they
+// were made and stops at the first one that still can't reach the server.
+// Actions the server rejects are dropped, replaying them wouldn't succeed
+// either, and so are the ones which expired while waiting.
+func (a *API) DrainQueue(ctx context.Context) error {
+	loginInfo, err := a.tokens.LoginInfo()
+	if err != nil {
+		return err
+	}
+	var pendingActions []models.PendingAction
+	result := a.db.Where("server_url = ? AND login_info_id = ?", a.baseUrl, loginInfo.ID).Order("id").Find(&pendingActions)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	for _, pendingAction := range pendingActions {
+		if !pendingAction.ExpiresAt.IsZero() && time.Now().After(pendingAction.ExpiresAt) {
+			log.Info().Msg("dropping expired queued action: " + pendingAction.Action)
+			result := a.db.Unscoped().Delete(&pendingAction)
+			if result.Error != nil {
+				return result.Error
+			}
+			continue
+		}
+		r := request{
+			method: pendingAction.Method,
+			action: pendingAction.Action,
+		}
+		_, err = a.send(ctx, r, pendingAction.Path, loginInfo.Token.String(), pendingAction.Body)
+		if isConnectivityError(err) {
+			return err
+		}
+		if err != nil {
+			log.Warn().Msg("dropping queued action, " + fmt.Sprint(err))
+		} else {
+			log.Info().Msg("sent queued action: " + pendingAction.Action)
+		}
+		result := a.db.Unscoped().Delete(&pendingAction)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// RunQueue periodically tries to drain the queue until ctx is cancelled.
+func (a *API) RunQueue(ctx context.Context) {
+	ticker := time.NewTicker(queueDrainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := a.PendingActions()
+			if err != nil || count == 0 {
+				continue
+			}
+			err = a.DrainQueue(ctx)
+			if err != nil {
+				log.Debug().Msg("couldn't drain action queue: " + fmt.Sprint(err))
+			}
+		}
+	}
+}
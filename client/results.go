@@ -0,0 +1,63 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"github.com/paulmach/orb/geojson"
+
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// GameResults is the summary of a finished game.
+type GameResults struct {
+	RunStartTime time.Time
+	// when the hider was found
+	EndTime time.Time
+	// the hiding zone the hider actually chose
+	HidingZone  *geojson.FeatureCollection
+	History     History
+	CardsPlayed []sharedModels.Card
+	CursesCast  []sharedModels.Card
+}
+
+// HidingTime is the time from the end of the run phase, which lasted
+// runDuration, until the hider was found.
+func (r GameResults) HidingTime(runDuration time.Duration) time.Duration {
+	return r.EndTime.Sub(r.RunStartTime.Add(runDuration))
+}
+
+var ErrGameNotFinished = errors.New("The game isn't finished yet")
+
+func (a *API) GetResults(ctx context.Context) (GameResults, error) {
+	results, err := doJSON[GameResults](ctx, a, request{
+		method:      "GET",
+		path:        "/results",
+		lobbyScoped: true,
+		action:      "getting game results",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusConflict:   ErrGameNotFinished,
+		},
+	})
+	results.RunStartTime = a.ToLocal(results.RunStartTime)
+	results.EndTime = a.ToLocal(results.EndTime)
+	results.History = results.History.toLocal(a)
+	return results, err
+}
+
+// Rematch creates a new lobby for the players of the finished game.
+func (a *API) Rematch(ctx context.Context) (sharedModels.LobbyCreationResponse, error) {
+	return doJSON[sharedModels.LobbyCreationResponse](ctx, a, request{
+		method:      "POST",
+		path:        "/rematch",
+		lobbyScoped: true,
+		action:      "starting rematch",
+		errors: statusErrors{
+			http.StatusBadRequest: ErrLobbyNotFound,
+			http.StatusConflict:   ErrGameNotFinished,
+		},
+	})
+}
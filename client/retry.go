@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultRetries = 3
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 8 * time.Second
+)
+
+// retryableStatus are the status codes of a server that is only temporarily
+// unavailable, e.g. while it restarts behind a reverse proxy.
+var retryableStatus = map[int]bool{
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// sendWithRetry sends the request up to attempts times, doubling the wait
+// between attempts, as long as the error is one that might go away.
+func (a *API) sendWithRetry(ctx context.Context, r request, path string, token string, body []byte, attempts int) ([]byte, error) {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		response, err := a.send(ctx, r, path, token, body)
+		if err == nil || attempt >= attempts || !isRetryable(err) {
+			return response, err
+		}
+		log.Debug().Msg(r.action + " failed on attempt " + fmt.Sprint(attempt) + ", retrying in " + fmt.Sprint(backoff) + ": " + fmt.Sprint(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func isRetryable(err error) bool {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return retryableStatus[apiError.Status]
+	}
+	return isConnectivityError(err)
+}
+
+// isConnectivityError reports whether err means the server couldn't be
+// reached at all, as opposed to the server answering with an error.
+func isConnectivityError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netError net.Error
+	return errors.As(err, &netError)
+}
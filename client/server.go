@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// APIVersion is the version of the server API this client speaks.
+const APIVersion = 1
+
+// ServerInfo describes a server, as returned by /version.
+type ServerInfo struct {
+	Version    string
+	APIVersion int
+	// set for servers from before /version existed, their API version is
+	// unknown
+	Legacy bool `json:"-"`
+}
+
+var (
+	ErrIncompatibleServer = errors.New("The server runs an incompatible version. Update the app or use a different server")
+	ErrProbeFailed        = errors.New("Couldn't connect to the server")
+)
+
+// Probe checks that the server is reachable and speaks a compatible API
+// version. It doesn't need a login. Servers without /version are assumed to
+// be compatible.
+func (a *API) Probe(ctx context.Context) (ServerInfo, error) {
+	info, err := doJSON[ServerInfo](ctx, a, request{
+		method:    "GET",
+		path:      "/version",
+		anonymous: true,
+		action:    "connecting to server",
+	})
+	if isMissingEndpoint(err) {
+		return ServerInfo{Legacy: true}, nil
+	}
+	if err != nil {
+		return info, fmt.Errorf("%w: %w", ErrProbeFailed, err)
+	}
+	if info.APIVersion != APIVersion {
+		return info, fmt.Errorf("%w (server API version %d, app API version %d)", ErrIncompatibleServer, info.APIVersion, APIVersion)
+	}
+	return info, nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrSessionExpired is returned for requests the server rejected because the
+// session is no longer valid. If a reauthentication handler is configured,
+// the user is already asked to log in again and the request is replayed
+// afterwards.
+var ErrSessionExpired = errors.New("Your session expired. Log in again to continue.")
+
+// sessionExpired reports whether the saved expiry of the session has passed,
+// so sending the request would only be rejected.
+func sessionExpired(loginInfo models.LoginInfo) bool {
+	return !loginInfo.Expiry.IsZero() && time.Now().After(loginInfo.Expiry)
+}
+
+// rejectsSession reports whether the server answered with 401, or with 403
+// on an endpoint that doesn't use 403 for something more specific like the
+// wrong role.
+func rejectsSession(r request, err error) bool {
+	var apiError *APIError
+	if !errors.As(err, &apiError) {
+		return false
+	}
+	switch apiError.Status {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusForbidden:
+		specific, ok := r.errors[http.StatusForbidden]
+		return !ok || specific == ErrUnauthenticated
+	}
+	return false
+}
+
+// sessionRejected hands the request to the reauthentication handler, which
+// replays changes once the user logged in again. Reads aren't replayed, their
+// callers fetch again anyway.
+func (a *API) sessionRejected(r request, err error) error {
+	if a.reauthenticate == nil {
+		return err
+	}
+	log.Info().Msg("session rejected while " + r.action + ", asking to log in again")
+	body, _ := json.Marshal(r.body)
+	key := r.method + " " + r.path + " " + string(body)
+	if r.method == http.MethodGet {
+		a.reauthenticate(key, nil)
+	} else {
+		a.reauthenticate(key, func(ctx context.Context) error {
+			_, err := a.do(ctx, r)
+			return err
+		})
+	}
+	return fmt.Errorf("%w (%w)", ErrSessionExpired, err)
+}
+
+// RefreshSession exchanges the current session for a new one with a later
+// expiry, without asking for the password again.
+func (a *API) RefreshSession(ctx context.Context) (sharedModels.SessionToken, error) {
+	session, err := doJSON[sharedModels.SessionToken](ctx, a, request{
+		method:   "POST",
+		path:     "/refresh",
+		noReplay: true,
+		action:   "refreshing session",
+	})
+	session.Expiry = a.ToLocal(session.Expiry)
+	return session, err
+}
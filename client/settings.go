@@ -0,0 +1,132 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// QuestionCategory is a group of questions that can be switched off for a
+// lobby.
+type QuestionCategory string
+
+const (
+	CategoryMatching    QuestionCategory = "matching"
+	CategoryRelative    QuestionCategory = "relative"
+	CategoryThermometer QuestionCategory = "thermometer"
+	CategoryRadar       QuestionCategory = "radar"
+)
+
+var QuestionCategories = []QuestionCategory{
+	CategoryMatching,
+	CategoryRelative,
+	CategoryThermometer,
+	CategoryRadar,
+}
+
+// PlayArea is a preset for the area the game is played in.
+type PlayArea string
+
+const (
+	PlayAreaBerlin PlayArea = "berlin"
+	// the area inside the S-Bahn ring
+	PlayAreaRing PlayArea = "ring"
+)
+
+var PlayAreas = []PlayArea{PlayAreaBerlin, PlayAreaRing}
+
+// LobbySettings are the rules of a lobby, chosen when it is created.
+type LobbySettings struct {
+	RunDuration        time.Duration
+	MaxHandSize        int
+	QuestionCategories []QuestionCategory
+	PlayArea           PlayArea
+	// radii of the radar questions in meters
+	RadarRadii []float64
+	// size of the seeker team, zero in lobbies created before seeker teams,
+	// which allow one seeker
+	MaxSeekers int
+}
+
+var (
+	ErrInvalidRunDuration = errors.New("The run phase has to last at least one minute")
+	ErrInvalidHandSize    = errors.New("The hand has to hold at least one card")
+	ErrNoQuestions        = errors.New("At least one question category has to be allowed")
+	ErrInvalidRadius      = errors.New("Radar radii have to be positive")
+	ErrInvalidTeamSize    = errors.New("The seeker team needs at least one seeker")
+)
+
+// DefaultLobbySettings are the rules suggested for new lobbies.
+func DefaultLobbySettings() LobbySettings {
+	return LobbySettings{
+		RunDuration:        sharedModels.RunDuration,
+		MaxHandSize:        sharedModels.MaxHandSize,
+		QuestionCategories: slices.Clone(QuestionCategories),
+		PlayArea:           PlayAreaBerlin,
+		RadarRadii:         []float64{200, 500, 1000, 2500, 5000, 10000, 15000},
+		MaxSeekers:         3,
+	}
+}
+
+// LegacyLobbySettings are the rules of lobbies on servers that don't know
+// about settings, with the single seeker those servers allow.
+func LegacyLobbySettings() LobbySettings {
+	settings := DefaultLobbySettings()
+	settings.MaxSeekers = 0
+	return settings
+}
+
+// SeekerSlots returns how many seekers may join the lobby.
+func (s LobbySettings) SeekerSlots() int {
+	return max(s.MaxSeekers, 1)
+}
+
+// Allows reports whether questions of the category may be asked.
+func (s LobbySettings) Allows(category QuestionCategory) bool {
+	return slices.Contains(s.QuestionCategories, category)
+}
+
+func (s LobbySettings) Validate() error {
+	if s.RunDuration < time.Minute {
+		return ErrInvalidRunDuration
+	}
+	if s.MaxHandSize < 1 {
+		return ErrInvalidHandSize
+	}
+	if len(s.QuestionCategories) == 0 {
+		return ErrNoQuestions
+	}
+	for _, radius := range s.RadarRadii {
+		if radius <= 0 {
+			return ErrInvalidRadius
+		}
+	}
+	if s.MaxSeekers < 1 {
+		return ErrInvalidTeamSize
+	}
+	return nil
+}
+
+// GetLobbySettings returns the rules of the lobby. Lobbies on servers that
+// don't know about settings use the legacy ones.
+func (a *API) GetLobbySettings(ctx context.Context, lobbyToken string) (LobbySettings, error) {
+	settings, err := doJSON[LobbySettings](ctx, a, request{
+		method: "GET",
+		path:   "/lobby/" + lobbyToken + "/settings",
+		action: "getting lobby settings",
+		errors: lobbyErrors,
+	})
+	if isMissingEndpoint(err) {
+		return LegacyLobbySettings(), nil
+	}
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.Status == http.StatusNotFound {
+		return settings, fmt.Errorf("%w (%w)", ErrLobbyNotFound, err)
+	}
+	return settings, err
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var errStreamClosed = errors.New("event stream closed by server")
+
+// maxEventLineSize is the longest line of the stream that is read, full
+// histories or lobbies are sent in a single data line.
+const maxEventLineSize = 4 << 20
+
+// stream reads server-sent events from /lobby/<token>/events until the
+// connection breaks. It resumes after the last received event by sending its
+// ID along. connected reports whether the server accepted the connection.
+func (s *eventStream) stream(ctx context.Context) (connected bool, err error) {
+	a := s.api
+	loginInfo, err := a.tokens.LoginInfo()
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseUrl+"/lobby/"+loginInfo.LobbyToken+"/events", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
+	req.Header.Set("Accept", "text/event-stream")
+	s.mu.Lock()
+	if s.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+	s.mu.Unlock()
+
+	res, err := a.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return false, request{action: "opening event stream"}.statusError(res)
+	}
+
+	var event Event
+	var data []string
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// a blank line ends the event
+			if len(data) > 0 {
+				if event.Type == "" {
+					event.Type = EventMessage
+				}
+				event.Data = json.RawMessage(strings.Join(data, "\n"))
+				localEvent, err := a.localizeEvent(event)
+				if err != nil {
+					log.Warn().Msg("failed converting the times of " + string(event.Type) + " event: " + fmt.Sprint(err))
+				}
+				s.dispatch(localEvent)
+			}
+			event = Event{}
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, used by servers as keep-alive
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = EventType(value)
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if scanner.Err() != nil {
+		return true, scanner.Err()
+	}
+	return true, errStreamClosed
+}
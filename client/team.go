@@ -0,0 +1,108 @@
+package client
+
+import (
+	"github.com/jkulzer/fib-server/sharedModels"
+
+	"github.com/paulmach/orb"
+
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrQuestionCooldown = errors.New("Your team has to wait before asking another question of this kind")
+
+// RoleSlot is a role in a lobby with the players holding it.
+type RoleSlot struct {
+	Players []string
+	// how many players may hold the role
+	Max int
+}
+
+// Open reports whether another player may take the role.
+func (s RoleSlot) Open() bool {
+	return len(s.Players) < s.Max
+}
+
+// RoleSlots are the roles of a lobby. There is always one hider, but several
+// seekers can hunt them as a team.
+type RoleSlots struct {
+	Hider   RoleSlot
+	Seekers RoleSlot
+}
+
+// Full reports whether no role is left to take.
+func (s RoleSlots) Full() bool {
+	return !s.Hider.Open() && !s.Seekers.Open()
+}
+
+// GetRoleSlots returns who holds which role in the lobby. Servers without
+// seeker teams only list the free roles, those are turned into one open slot
+// per role without any players.
+func (a *API) GetRoleSlots(ctx context.Context, lobbyToken string) (RoleSlots, error) {
+	slots, err := doJSON[RoleSlots](ctx, a, request{
+		method: "GET",
+		path:   "/lobby/" + lobbyToken + "/roleSlots",
+		action: "getting roles",
+		errors: lobbyErrors,
+	})
+	if !isMissingEndpoint(err) {
+		return slots, err
+	}
+
+	roles, err := a.GetRoles(ctx, lobbyToken)
+	if err != nil {
+		return RoleSlots{}, err
+	}
+	slots = RoleSlots{}
+	for _, role := range roles {
+		switch role {
+		case sharedModels.Hider:
+			slots.Hider.Max = 1
+		case sharedModels.Seeker:
+			slots.Seekers.Max = 1
+		}
+	}
+	return slots, nil
+}
+
+// Seeker is a member of the seeker team.
+type Seeker struct {
+	Username string
+	// nil until the seeker shared their location
+	Location *orb.Point
+	// when the location was shared
+	LocatedAt time.Time
+}
+
+// SeekerTeam is everyone hunting the hider. The question cooldowns are shared,
+// so a question asked by one seeker blocks that kind of question for all.
+type SeekerTeam struct {
+	Seekers []Seeker
+	// when the team may ask a question of the category again, missing while
+	// the category isn't cooling down
+	Cooldowns map[QuestionCategory]time.Time
+}
+
+// CooldownLeft returns how long the team has to wait before asking a question
+// of the category, zero if it may ask right away.
+func (t SeekerTeam) CooldownLeft(category QuestionCategory) time.Duration {
+	until, ok := t.Cooldowns[category]
+	if !ok {
+		return 0
+	}
+	return max(time.Until(until), 0)
+}
+
+// GetSeekerTeam returns the seekers of the lobby with their last locations.
+// Only seekers may see it.
+func (a *API) GetSeekerTeam(ctx context.Context) (SeekerTeam, error) {
+	team, err := doJSON[SeekerTeam](ctx, a, request{
+		method:      "GET",
+		path:        "/team",
+		lobbyScoped: true,
+		action:      "getting seeker team",
+		errors:      questionErrors,
+	})
+	return team.toLocal(a), err
+}
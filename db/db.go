@@ -4,7 +4,9 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/rs/zerolog/log"
@@ -14,12 +16,15 @@ import (
 	"fyne.io/fyne/v2/storage"
 
 	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
 	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/secrets"
 )
 
 func InitDB(app fyne.App, subPath string) env.Env {
 
-	dbPathUri := storage.NewFileURI(filepath.Join(app.Storage().RootURI().Path(), subPath+".db"))
+	rootPath := app.Storage().RootURI().Path()
+	dbPathUri := storage.NewFileURI(filepath.Join(rootPath, subPath+".db"))
 
 	fmt.Println("dev.jkulzer.findinberlin " + dbPathUri.Path())
 
@@ -28,14 +33,128 @@ func InitDB(app fyne.App, subPath string) env.Env {
 		log.Err(err).Msg("failed to create/open db")
 	}
 
-	err = db.AutoMigrate(&models.LoginInfo{})
+	err = db.AutoMigrate(&models.LoginInfo{}, &models.PendingAction{}, &models.ServerProfile{}, &models.KnownLobby{}, &models.Notification{}, &models.NotificationSetting{})
 	if err != nil {
 		log.Err(err)
 	}
 
+	// rows from before server profiles existed belong to the server that used
+	// to be hard-coded
+	for _, model := range []any{&models.LoginInfo{}, &models.PendingAction{}} {
+		result := db.Model(model).Where("server_url = ?", "").Update("server_url", env.DefaultUrl)
+		if result.Error != nil {
+			log.Err(result.Error).Msg("failed migrating rows without server url")
+		}
+	}
+
+	secretStore := openSecrets(filepath.Join(rootPath, subPath))
+	migrateTokens(db, secretStore)
+	migrateSingleLogins(db, secretStore)
+
 	env := env.Env{
-		DB: db,
+		DB:      db,
+		Secrets: secretStore,
 	}
 
 	return env
 }
+
+// openSecrets opens the encrypted secret store next to the database, with
+// its key in a file of its own.
+func openSecrets(basePath string) secrets.Store {
+	key, err := secrets.DeviceKey(basePath + ".key")
+	if errors.Is(err, secrets.ErrInvalidKey) {
+		// the secrets are lost without their key, the files are kept aside
+		// so they can still be recovered by hand
+		log.Error().Msg("device key is invalid, moving it and the secret store aside, the saved logins have to be entered again")
+		for _, suffix := range []string{".key", ".secrets"} {
+			err := os.Rename(basePath+suffix, basePath+suffix+".invalid")
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Err(err).Msg("failed moving " + basePath + suffix + " aside")
+			}
+		}
+		key, err = secrets.DeviceKey(basePath + ".key")
+	}
+	if err != nil {
+		// without a key the store can't be opened, and mustn't be replaced
+		log.Fatal().Err(err).Msg("failed reading device key")
+	}
+	store, err := secrets.NewFileStore(basePath+".secrets", key)
+	if err != nil {
+		// an unreadable store only costs the saved logins, it is kept aside
+		// like the invalid key above
+		log.Err(err).Msg("failed opening secret store, moving it aside and starting with an empty one")
+		err = os.Rename(basePath+".secrets", basePath+".secrets.bad")
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed moving secret store aside")
+		}
+		store, err = secrets.NewFileStore(basePath+".secrets", key)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed creating secret store")
+		}
+	}
+	return store
+}
+
+// migrateTokens moves the session tokens that older versions kept in plain
+// text in the database to the secret store.
+func migrateTokens(db *gorm.DB, store secrets.Store) {
+	if !db.Migrator().HasColumn(&models.LoginInfo{}, "token") {
+		return
+	}
+
+	var rows []struct {
+		ID        uint
+		ServerUrl string
+		Token     string
+	}
+	result := db.Table("login_infos").Select("id, server_url, token").Where("token IS NOT NULL AND token != '' AND token != ?", models.NullUuidString).Scan(&rows)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("failed reading plain text tokens")
+		return
+	}
+	for _, row := range rows {
+		err := store.Set(helpers.TokenKey(row.ID), row.Token)
+		if err != nil {
+			log.Err(err).Msg("failed moving token of " + row.ServerUrl + " to the secret store")
+			return
+		}
+	}
+
+	err := db.Migrator().DropColumn(&models.LoginInfo{}, "token")
+	if err != nil {
+		log.Err(err).Msg("failed removing plain text tokens")
+		return
+	}
+	log.Info().Msg("moved " + fmt.Sprint(len(rows)) + " plain text tokens to the secret store")
+}
+
+// migrateSingleLogins moves the data of versions with only one login per
+// server to that login: its token used to be stored per server and its
+// queued actions didn't belong to any login.
+func migrateSingleLogins(db *gorm.DB, store secrets.Store) {
+	var logins []models.LoginInfo
+	result := db.Find(&logins)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("failed reading logins")
+		return
+	}
+	for _, loginInfo := range logins {
+		serverKey := "token:" + loginInfo.ServerUrl
+		token, err := store.Get(serverKey)
+		if err == nil {
+			err = store.Set(helpers.TokenKey(loginInfo.ID), token)
+			if err == nil {
+				err = store.Delete(serverKey)
+			}
+			if err != nil {
+				log.Err(err).Msg("failed moving token of " + loginInfo.ServerUrl)
+			}
+		}
+
+		result := db.Model(&models.PendingAction{}).Where("server_url = ? AND login_info_id = 0", loginInfo.ServerUrl).Update("login_info_id", loginInfo.ID)
+		if result.Error != nil {
+			log.Err(result.Error).Msg("failed assigning queued actions of " + loginInfo.ServerUrl)
+		}
+	}
+}
@@ -2,9 +2,27 @@ package env
 
 import (
 	"gorm.io/gorm"
+
+	"context"
+	"net/http"
+
+	"github.com/jkulzer/fib-client/secrets"
 )
 
+// DefaultUrl is the server used when no other server was configured.
+const DefaultUrl = "http://localhost:3001"
+
 type Env struct {
 	DB  *gorm.DB
 	Url string
+	// Secrets holds the session tokens, which are kept out of the database
+	Secrets secrets.Store
+	// HTTPClient is used for all requests to the server, defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// Reauthenticate is called when the server rejects the session. key
+	// identifies the rejected request, equal requests share a key. replay
+	// sends it again once the user logged in anew, it is nil for reads, which
+	// their callers retry on their own. If Reauthenticate is nil, rejected
+	// sessions are only reported as errors.
+	Reauthenticate func(key string, replay func(ctx context.Context) error)
 }
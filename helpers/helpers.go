@@ -3,15 +3,23 @@ package helpers
 import (
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/secrets"
+	"github.com/jkulzer/fib-server/sharedModels"
 
-	fyne "fyne.io/fyne/v2"
+	"github.com/google/uuid"
 
 	"github.com/rs/zerolog/log"
 
+	"errors"
 	"fmt"
 	"io"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+var ErrNotLoggedIn = errors.New("Not logged in")
+
 func ReadHttpResponse(input io.ReadCloser) ([]byte, error) {
 	if b, err := io.ReadAll(input); err == nil {
 		return b, err
@@ -28,18 +36,124 @@ func ReadHttpResponseToString(input io.ReadCloser) (string, error) {
 	}
 }
 
-func GetAppConfig(env env.Env, parentWindow fyne.Window) (models.LoginInfo, error) {
+// TokenKey is the key of the session token of a login in the secret store.
+func TokenKey(loginInfoID uint) string {
+	return "token:" + fmt.Sprint(loginInfoID)
+}
+
+// activeLogin returns the login on the server of env that was used last.
+func activeLogin(env env.Env) (models.LoginInfo, error) {
 	var loginInfo models.LoginInfo
-	result := env.DB.First(&loginInfo)
-	if result.Error != nil {
-		log.Err(result.Error)
-		return models.LoginInfo{}, result.Error
-	} else if loginInfo.Token.String() == models.NullUuidString {
-		log.Warn().Msg("auth token uuid string in app config is null")
+	result := env.DB.Where("server_url = ?", env.Url).Order("last_used desc, id desc").First(&loginInfo)
+	return loginInfo, result.Error
+}
+
+// GetAppConfig returns the active login on the server of env, together with
+// its session token.
+func GetAppConfig(env env.Env) (models.LoginInfo, error) {
+	loginInfo, err := activeLogin(env)
+	if err != nil {
+		log.Err(err)
+		return models.LoginInfo{}, err
+	}
+
+	token, err := env.Secrets.Get(TokenKey(loginInfo.ID))
+	if errors.Is(err, secrets.ErrNotFound) {
+		log.Warn().Msg("no auth token saved for " + loginInfo.Username + " on " + env.Url)
 		log.Debug().Msg(fmt.Sprint(loginInfo))
-		// dialog.ShowError(result.Error, parentWindow)
-		return models.LoginInfo{}, result.Error
+		return models.LoginInfo{}, ErrNotLoggedIn
+	}
+	if err != nil {
+		return models.LoginInfo{}, err
+	}
+	loginInfo.Token, err = uuid.Parse(token)
+	if err != nil || loginInfo.Token.String() == models.NullUuidString {
+		log.Warn().Msg("auth token uuid string in app config is invalid")
+		return models.LoginInfo{}, ErrNotLoggedIn
+	}
+	return loginInfo, nil
+}
+
+// SaveSession stores the session the server of env handed out for the user
+// and makes the user the active account. An empty username updates the
+// session of the active account. The lobby and role of an existing login
+// are kept.
+func SaveSession(env env.Env, username string, session sharedModels.SessionToken) error {
+	var loginInfo models.LoginInfo
+	if username == "" {
+		var err error
+		loginInfo, err = activeLogin(env)
+		if err != nil {
+			return err
+		}
 	} else {
-		return loginInfo, nil
+		loginInfo = models.LoginInfo{ServerUrl: env.Url, Username: username}
+		result := env.DB.Where("server_url = ? AND username = ?", env.Url, username).FirstOrInit(&loginInfo)
+		if result.Error != nil {
+			return result.Error
+		}
+		loginInfo.LastUsed = time.Now()
+	}
+	loginInfo.Expiry = session.Expiry
+	result := env.DB.Save(&loginInfo)
+	if result.Error != nil {
+		return result.Error
+	}
+	return env.Secrets.Set(TokenKey(loginInfo.ID), session.Token.String())
+}
+
+// ListAccounts returns the logins on the server of env, the active one
+// first. The token is empty for logins without a saved session.
+func ListAccounts(env env.Env) ([]models.LoginInfo, error) {
+	var accounts []models.LoginInfo
+	result := env.DB.Where("server_url = ?", env.Url).Order("last_used desc, id desc").Find(&accounts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for i, loginInfo := range accounts {
+		token, err := env.Secrets.Get(TokenKey(loginInfo.ID))
+		if err == nil {
+			accounts[i].Token, _ = uuid.Parse(token)
+		}
+	}
+	return accounts, nil
+}
+
+// UseAccount makes the login the active account on its server.
+func UseAccount(env env.Env, loginInfo models.LoginInfo) error {
+	result := env.DB.Model(&loginInfo).Update("last_used", time.Now())
+	return result.Error
+}
+
+// RemoveAccount forgets the login, its session and its queued actions on
+// this device.
+func RemoveAccount(env env.Env, loginInfo models.LoginInfo) error {
+	err := env.Secrets.Delete(TokenKey(loginInfo.ID))
+	if err != nil {
+		return err
+	}
+	return env.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("login_info_id = ?", loginInfo.ID).Delete(&models.PendingAction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		result = tx.Unscoped().Where("login_info_id = ?", loginInfo.ID).Delete(&models.KnownLobby{})
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Delete(&loginInfo).Error
+	})
+}
+
+// DeleteAppConfig logs the active account out of the server of env. The
+// account used before it becomes the active one.
+func DeleteAppConfig(env env.Env) error {
+	loginInfo, err := activeLogin(env)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
+	return RemoveAccount(env, loginInfo)
 }
@@ -0,0 +1,67 @@
+//go:build android
+// +build android
+
+package invite
+
+import (
+	"unsafe"
+
+	"fyne.io/fyne/v2/driver"
+)
+
+/*
+#include <jni.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+
+// launchData returns the data uri of the intent that started the activity,
+// NULL if there is none. The caller frees the copy.
+static char *launchData(uintptr_t jni_env, uintptr_t ctx) {
+  JNIEnv *env = (JNIEnv *)jni_env;
+  jobject activity = (jobject)ctx;
+
+  jclass activityClass = (*env)->GetObjectClass(env, activity);
+  jmethodID getIntent = (*env)->GetMethodID(env, activityClass, "getIntent",
+                                            "()Landroid/content/Intent;");
+  jobject intent = (*env)->CallObjectMethod(env, activity, getIntent);
+  if (intent == NULL) {
+    return NULL;
+  }
+
+  jclass intentClass = (*env)->GetObjectClass(env, intent);
+  jmethodID getDataString = (*env)->GetMethodID(env, intentClass,
+                                                "getDataString",
+                                                "()Ljava/lang/String;");
+  jstring data = (jstring)(*env)->CallObjectMethod(env, intent, getDataString);
+  if (data == NULL) {
+    return NULL;
+  }
+
+  const char *chars = (*env)->GetStringUTFChars(env, data, NULL);
+  char *copy = strdup(chars);
+  (*env)->ReleaseStringUTFChars(env, data, chars);
+  return copy;
+}
+*/
+import "C"
+
+// LaunchLink returns the invite link the app was opened with, empty if it
+// was started without one.
+func LaunchLink() string {
+	var link string
+	driver.RunNative(func(ctx any) error {
+		ac, ok := ctx.(*driver.AndroidContext)
+		if !ok {
+			return nil
+		}
+		data := C.launchData(C.uintptr_t(ac.Env), C.uintptr_t(ac.Ctx))
+		if data == nil {
+			return nil
+		}
+		defer C.free(unsafe.Pointer(data))
+		link = C.GoString(data)
+		return nil
+	})
+	return link
+}
@@ -0,0 +1,108 @@
+// Package invite builds and reads the links that invite someone into a
+// lobby. A link carries the server and the lobby token, so joining works
+// even if the invited player uses another server right now.
+package invite
+
+import (
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	goqrcode "github.com/skip2/go-qrcode"
+
+	"github.com/jkulzer/fib-client/servers"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+const (
+	Scheme = "fib"
+	// host of the link, fib://join?...
+	joinHost = "join"
+)
+
+var (
+	ErrInvalidInvite = errors.New("This is neither an invite link nor a lobby code")
+	ErrNoQRCode      = errors.New("No QR code found in the image")
+)
+
+// lobbyCodeRegex only matches whole codes, whether or not the server's
+// pattern is anchored, so a link never passes as a code.
+var lobbyCodeRegex = regexp.MustCompile("^(?:" + sharedModels.LobbyCodeRegex + ")$")
+
+// Invite into a lobby. An empty ServerUrl means the server the app is
+// connected to, that's the case for plain lobby codes.
+type Invite struct {
+	ServerUrl  string
+	LobbyToken string
+}
+
+// Link returns the deep link of the lobby on the server, e.g.
+// fib://join?server=https%3A%2F%2Ffib.example.com&lobby=AG5L3T.
+func Link(serverUrl, lobbyToken string) string {
+	query := url.Values{}
+	query.Set("server", serverUrl)
+	query.Set("lobby", lobbyToken)
+	link := url.URL{
+		Scheme:   Scheme,
+		Host:     joinHost,
+		RawQuery: query.Encode(),
+	}
+	return link.String()
+}
+
+// Parse reads an invite link or a plain lobby code, as players paste
+// whatever they got sent.
+func Parse(text string) (Invite, error) {
+	text = strings.TrimSpace(text)
+	if lobbyCodeRegex.MatchString(strings.ToUpper(text)) {
+		return Invite{LobbyToken: strings.ToUpper(text)}, nil
+	}
+
+	link, err := url.Parse(text)
+	if err != nil || link.Scheme != Scheme || link.Host != joinHost {
+		return Invite{}, ErrInvalidInvite
+	}
+	query := link.Query()
+	lobbyToken := strings.ToUpper(query.Get("lobby"))
+	if !lobbyCodeRegex.MatchString(lobbyToken) {
+		return Invite{}, ErrInvalidInvite
+	}
+	invite := Invite{LobbyToken: lobbyToken}
+	if query.Get("server") != "" {
+		invite.ServerUrl, err = servers.NormalizeUrl(query.Get("server"))
+		if err != nil {
+			return Invite{}, err
+		}
+	}
+	return invite, nil
+}
+
+// QRCode renders the link as a PNG with the given width and height in pixels.
+func QRCode(link string, size int) ([]byte, error) {
+	return goqrcode.Encode(link, goqrcode.Medium, size)
+}
+
+// Decode reads the invite from a PNG or JPEG image of its QR code, like a
+// screenshot or a photo of another player's screen.
+func Decode(r io.Reader) (Invite, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return Invite{}, err
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return Invite{}, err
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		return Invite{}, ErrNoQRCode
+	}
+	return Parse(result.GetText())
+}
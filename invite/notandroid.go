@@ -0,0 +1,10 @@
+//go:build !android
+// +build !android
+
+package invite
+
+// LaunchLink returns the invite link the app was opened with. Desktops pass
+// it with the -join flag instead, so it is always empty here.
+func LaunchLink() string {
+	return ""
+}
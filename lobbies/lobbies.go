@@ -0,0 +1,37 @@
+// Package lobbies remembers the lobbies an account joined, so the player can
+// find their way back into a game.
+package lobbies
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jkulzer/fib-client/models"
+)
+
+// List returns the lobbies the account joined, the most recently joined
+// first.
+func List(db *gorm.DB, loginInfoID uint) ([]models.KnownLobby, error) {
+	var known []models.KnownLobby
+	result := db.Where("login_info_id = ?", loginInfoID).Order("joined_at desc").Find(&known)
+	return known, result.Error
+}
+
+// Remember records that the account joined the lobby. Joining a known lobby
+// again only updates the time it was joined.
+func Remember(db *gorm.DB, loginInfoID uint, lobbyToken string) error {
+	known := models.KnownLobby{LoginInfoID: loginInfoID, LobbyToken: lobbyToken}
+	result := db.Where("login_info_id = ? AND lobby_token = ?", loginInfoID, lobbyToken).FirstOrInit(&known)
+	if result.Error != nil {
+		return result.Error
+	}
+	known.JoinedAt = time.Now()
+	return db.Save(&known).Error
+}
+
+// Forget removes the lobby from the list of the account, e.g. after leaving
+// it.
+func Forget(db *gorm.DB, loginInfoID uint, lobbyToken string) error {
+	return db.Unscoped().Where("login_info_id = ? AND lobby_token = ?", loginInfoID, lobbyToken).Delete(&models.KnownLobby{}).Error
+}
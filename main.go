@@ -4,42 +4,76 @@ import (
 	"fyne.io/fyne/v2/app"
 
 	"github.com/jkulzer/fib-client/db"
-	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/invite"
+	"github.com/jkulzer/fib-client/router"
+	"github.com/jkulzer/fib-client/servers"
 	"github.com/jkulzer/fib-client/widgets"
 
 	"github.com/rs/zerolog/log"
 
-	"os"
+	"flag"
+
+	"gorm.io/gorm"
 )
 
 func main() {
 	app := app.NewWithID("dev.jkulzer.findinberlin")
 	w := app.NewWindow("FindInBerlin")
 
+	serverFlag := flag.String("server", "", "url of the server to connect to, e.g. https://fib.example.com")
+	joinFlag := flag.String("join", "", "invite link or lobby code to join, e.g. fib://join?server=...&lobby=AG5L3T")
+	flag.Parse()
+
 	var dbSubpath string
-	if len(os.Args) >= 2 {
-		log.Info().Msg("db subpath: " + os.Args[1])
-		dbSubpath = os.Args[1]
+	if flag.NArg() >= 1 {
+		log.Info().Msg("db subpath: " + flag.Arg(0))
+		dbSubpath = flag.Arg(0)
 	} else {
 		dbSubpath = "sqlite"
 	}
 
 	env := db.InitDB(app, dbSubpath)
+	env.Url = serverUrl(env.DB, *serverFlag)
 
-	env.Url = "http://localhost:3001"
-	var loginInfo models.LoginInfo
-	result := env.DB.First(&loginInfo)
-	if result.Error != nil {
-		log.Warn().Msg("couldn't find token, starting login sequence")
-		loginRegisterTabs := widgets.GetLoginRegisterTabs(env, w)
-		w.SetContent(loginRegisterTabs)
+	appRouter := widgets.NewRouter(env, w)
+	// with several accounts on the server, let the user pick one first
+	accounts, err := helpers.ListAccounts(env)
+	if err == nil && len(accounts) > 1 {
+		appRouter.Navigate(router.Accounts)
 	} else {
-		if loginInfo.LobbyToken != "" {
-			w.SetContent(widgets.NewGameWidget(env, w))
-		} else {
-			w.SetContent(widgets.NewLobbyWidget(env, w))
-		}
+		appRouter.Sync()
 	}
+	if *joinFlag != "" {
+		widgets.OpenInvite(w, *joinFlag)
+	}
+	// on Android invite links open the app instead
+	app.Lifecycle().SetOnStarted(func() {
+		link := invite.LaunchLink()
+		if link != "" && *joinFlag == "" {
+			widgets.OpenInvite(w, link)
+		}
+	})
 
 	w.ShowAndRun()
 }
+
+// serverUrl returns the server given on the command line, the last used
+// server or the default server, in that order.
+func serverUrl(db *gorm.DB, serverFlag string) string {
+	if serverFlag != "" {
+		profile, err := servers.Use(db, "", serverFlag)
+		if err == nil {
+			return profile.Url
+		}
+		log.Err(err).Msg("ignoring invalid server " + serverFlag)
+	}
+
+	profile, err := servers.LastOrDefault(db)
+	if err != nil {
+		log.Err(err).Msg("failed saving default server")
+		return env.DefaultUrl
+	}
+	return profile.Url
+}
@@ -1,6 +1,7 @@
 package mapWidget
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
@@ -13,8 +14,8 @@ import (
 
 	"github.com/rs/zerolog/log"
 
-	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/state"
 
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/geojson"
@@ -32,10 +33,16 @@ import (
 
 	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dkit"
 )
 
 const tileSize = 256
 
+// ShowRequestError presents a failed request of the map. The widgets package
+// replaces it with its own, which knows about queued actions and expired
+// sessions.
+var ShowRequestError = dialog.ShowError
+
 // Map widget renders an interactive map using OpenStreetMap tile data.
 type Map struct {
 	widget.BaseWidget
@@ -56,12 +63,23 @@ type Map struct {
 
 	lineColor color.Color
 
-	env          env.Env
+	store        *state.Store
 	parentWindow *fyne.Window
 
 	featureCollection *geojson.FeatureCollection // overlay to render
+	highlight         *geojson.FeatureCollection // drawn on top of the overlay in highlightColor
+	highlightColor    color.Color
+	markers           []Marker // drawn on top of everything else
+}
+
+// Marker is a point drawn on top of the map, e.g. the location of a player.
+type Marker struct {
+	Location orb.Point
+	Color    color.Color
 }
 
+const markerRadius = 8
+
 type linePos struct {
 	startX float32
 	startY float32
@@ -113,6 +131,15 @@ func WithScrollButtons(enable bool) MapOption {
 	}
 }
 
+// WithHighlight draws the feature collection on top of the map data, e.g. to
+// compare the hiding zone with the area the seekers narrowed down.
+func WithHighlight(fc *geojson.FeatureCollection, highlightColor color.Color) MapOption {
+	return func(m *Map) {
+		m.highlight = fc
+		m.highlightColor = highlightColor
+	}
+}
+
 // WithHTTPClient configures the map to use a custom http client.
 func WithHTTPClient(client *http.Client) MapOption {
 	return func(m *Map) {
@@ -120,11 +147,11 @@ func WithHTTPClient(client *http.Client) MapOption {
 	}
 }
 
-// NewMap creates a new instance of the map widget.
-func NewMap(fc *geojson.FeatureCollection, env env.Env, parentWindow *fyne.Window) *Map {
+// NewMap creates a new instance of the map widget, drawing the map data of
+// the lobby state.
+func NewMap(ctx context.Context, env env.Env, parentWindow *fyne.Window) *Map {
 	m := &Map{
 		cl:           &http.Client{},
-		env:          env,
 		parentWindow: parentWindow,
 	}
 	WithOsmTiles()(m)
@@ -144,8 +171,20 @@ func NewMap(fc *geojson.FeatureCollection, env env.Env, parentWindow *fyne.Windo
 	m.zoom = 10
 	m.x = 38
 	m.y = -176
-	m.featureCollection = fc
 	m.ExtendBaseWidget(m)
+
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state")
+		dialog.ShowError(err, *parentWindow)
+		return m
+	}
+	m.store = store
+	m.featureCollection = store.Map()
+	store.OnChange(ctx, state.MapChanged, func() {
+		m.SetFeatureCollection(store.Map())
+		m.BaseWidget.Refresh()
+	})
 	return m
 }
 
@@ -153,9 +192,22 @@ func (m *Map) SetFeatureCollection(fc *geojson.FeatureCollection) {
 	m.featureCollection = fc
 }
 
+// SetHighlight sets the feature collection drawn on top of the map data.
+func (m *Map) SetHighlight(fc *geojson.FeatureCollection, highlightColor color.Color) {
+	m.highlight = fc
+	m.highlightColor = highlightColor
+	m.BaseWidget.Refresh()
+}
+
+// SetMarkers replaces the markers drawn on the map.
+func (m *Map) SetMarkers(markers []Marker) {
+	m.markers = markers
+	m.BaseWidget.Refresh()
+}
+
 // NewMapWithOptions creates a new instance of the map widget with provided map options.
-func NewMapWithOptions(fc *geojson.FeatureCollection, env env.Env, parentWindow *fyne.Window, opts ...MapOption) *Map {
-	m := NewMap(fc, env, parentWindow)
+func NewMapWithOptions(ctx context.Context, env env.Env, parentWindow *fyne.Window, opts ...MapOption) *Map {
+	m := NewMap(ctx, env, parentWindow)
 	for _, opt := range opts {
 		opt(m)
 	}
@@ -374,20 +426,9 @@ func (m *Map) overlay(w, h int) image.Image {
 	gc.Clear()
 	gc.DrawImage(m.pixels)
 
-	for _, feature := range m.featureCollection.Features {
-		switch feature.Geometry.GeoJSONType() {
-		case "LineString":
-			lineString := feature.Geometry.(orb.LineString)
-			m.drawLineString(lineString, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight, gc)
-		case "Polygon":
-			renderPolygon(feature.Geometry, gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
-		case "MultiPolygon":
-			multiPolygon, _ := feature.Geometry.(orb.MultiPolygon)
-			for _, polygon := range multiPolygon {
-				renderPolygon(polygon, gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
-			}
-		}
-	}
+	m.drawFeatures(m.featureCollection, m.lineColor, color.RGBA{83, 118, 245, 255}, gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
+	m.drawFeatures(m.highlight, m.highlightColor, m.highlightColor, gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
+	m.drawMarkers(gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
 
 	// gc.Clear()
 
@@ -411,6 +452,37 @@ func (m *Map) overlay(w, h int) image.Image {
 	return m.pixels
 }
 
+func (m *Map) drawFeatures(fc *geojson.FeatureCollection, lineColor, fillColor color.Color, gc *draw2dimg.GraphicContext, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth, projCoordPerPixelHeight float64) {
+	if fc == nil {
+		return
+	}
+	for _, feature := range fc.Features {
+		switch feature.Geometry.GeoJSONType() {
+		case "LineString":
+			lineString := feature.Geometry.(orb.LineString)
+			drawLineString(lineString, lineColor, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight, gc)
+		case "Polygon":
+			renderPolygon(feature.Geometry, fillColor, gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
+		case "MultiPolygon":
+			multiPolygon, _ := feature.Geometry.(orb.MultiPolygon)
+			for _, polygon := range multiPolygon {
+				renderPolygon(polygon, fillColor, gc, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
+			}
+		}
+	}
+}
+
+func (m *Map) drawMarkers(gc *draw2dimg.GraphicContext, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth, projCoordPerPixelHeight float64) {
+	for _, marker := range m.markers {
+		x, y := pixelPosition(marker.Location, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
+		gc.SetFillColor(marker.Color)
+		gc.SetStrokeColor(color.White)
+		gc.SetLineWidth(2)
+		draw2dkit.Circle(gc, float64(x), float64(y), markerRadius)
+		gc.FillStroke()
+	}
+}
+
 func (m *Map) zoomInStep() {
 	m.zoom++
 	m.x *= 2
@@ -435,10 +507,10 @@ func (m *Map) GetZoom() int {
 	return m.zoom
 }
 
-func (m *Map) drawLineString(lineString orb.LineString, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth, projCoordPerPixelHeight float64, gc *draw2dimg.GraphicContext) {
+func drawLineString(lineString orb.LineString, lineColor color.Color, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth, projCoordPerPixelHeight float64, gc *draw2dimg.GraphicContext) {
 	linePositions := getLinePositions(lineString, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
 	for _, position := range linePositions {
-		m.drawLine(position, gc)
+		drawLine(position, lineColor, gc)
 	}
 }
 
@@ -450,31 +522,30 @@ func getLinePositions(lineString orb.LineString, middlePointProj orb.Point, size
 
 			endPoint := lineString[lsIndex+1]
 
-			projPoint := project.Point(point, project.WGS84.ToMercator)
-			projEndPoint := project.Point(endPoint, project.WGS84.ToMercator)
-
-			startLonDiff := projPoint[0] - middlePointProj[0]
-			startLatDiff := middlePointProj[1] - projPoint[1]
-
-			endLonDiff := projEndPoint[0] - middlePointProj[0]
-			endLatDiff := middlePointProj[1] - projEndPoint[1]
-
-			scaleAddX := float32(size.Width / 1.54)
-			scaleAddY := float32(size.Height / 1.54)
-
-			startX := float32(startLonDiff/projCoordPerPixelWidth) + scaleAddX
-			startY := float32(startLatDiff/projCoordPerPixelHeight) + scaleAddY
-			endX := float32(endLonDiff/projCoordPerPixelWidth) + scaleAddX
-			endY := float32(endLatDiff/projCoordPerPixelHeight) + scaleAddY
+			startX, startY := pixelPosition(point, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
+			endX, endY := pixelPosition(endPoint, middlePointProj, size, projCoordPerPixelWidth, projCoordPerPixelHeight)
 			linePositions = append(linePositions, linePos{startX, startY, endX, endY})
 		}
 	}
 	return linePositions
 }
 
-func (m *Map) drawLine(linePosition linePos, gc *draw2dimg.GraphicContext) {
-	gc.SetFillColor(m.lineColor)
-	gc.SetStrokeColor(m.lineColor)
+// pixelPosition returns where the point is drawn on the overlay.
+func pixelPosition(point orb.Point, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth, projCoordPerPixelHeight float64) (float32, float32) {
+	projPoint := project.Point(point, project.WGS84.ToMercator)
+
+	lonDiff := projPoint[0] - middlePointProj[0]
+	latDiff := middlePointProj[1] - projPoint[1]
+
+	scaleAddX := float32(size.Width / 1.54)
+	scaleAddY := float32(size.Height / 1.54)
+
+	return float32(lonDiff/projCoordPerPixelWidth) + scaleAddX, float32(latDiff/projCoordPerPixelHeight) + scaleAddY
+}
+
+func drawLine(linePosition linePos, lineColor color.Color, gc *draw2dimg.GraphicContext) {
+	gc.SetFillColor(lineColor)
+	gc.SetStrokeColor(lineColor)
 	gc.SetLineWidth(1)
 
 	gc.MoveTo(float64(linePosition.startX), float64(linePosition.startY))
@@ -507,7 +578,7 @@ func adjustTransparency(src image.Image, alphaFactor float64) *image.NRGBA {
 	return dst
 }
 
-func renderPolygon(featureGeometry orb.Geometry, gc *draw2dimg.GraphicContext, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth float64, projCoordPerPixelHeight float64) {
+func renderPolygon(featureGeometry orb.Geometry, fillColor color.Color, gc *draw2dimg.GraphicContext, middlePointProj orb.Point, size fyne.Size, projCoordPerPixelWidth float64, projCoordPerPixelHeight float64) {
 	rings := []orb.Ring(featureGeometry.(orb.Polygon))
 	ringListLen := len(rings)
 	for ringIndex, ring := range rings {
@@ -530,7 +601,7 @@ func renderPolygon(featureGeometry orb.Geometry, gc *draw2dimg.GraphicContext, m
 		gc.SetFillRule(draw2d.FillRuleEvenOdd)
 		// gc.SetFillRule(draw2d.FillRuleWinding)
 
-		gc.SetFillColor(color.RGBA{83, 118, 245, 255})
+		gc.SetFillColor(fillColor)
 		gc.SetStrokeColor(color.Transparent)
 		gc.SetLineWidth(0)
 		for _, linePosition := range linePositions {
@@ -544,17 +615,16 @@ func renderPolygon(featureGeometry orb.Geometry, gc *draw2dimg.GraphicContext, m
 	gc.FillStroke()
 }
 
+// Refresh fetches the map data from the server right away instead of waiting
+// for the next update of the lobby state.
 func (m *Map) Refresh() {
-	mapData, err := client.GetMapData(m.env, *m.parentWindow)
-	if err != nil {
-		dialog.ShowError(err, *m.parentWindow)
-	}
-
-	fc, err := geojson.UnmarshalFeatureCollection(mapData)
-	if err != nil {
-		dialog.ShowError(err, *m.parentWindow)
+	if m.store != nil {
+		err := m.store.RefreshMap(context.Background())
+		if err != nil {
+			ShowRequestError(err, *m.parentWindow)
+		}
+		m.SetFeatureCollection(m.store.Map())
 	}
-	m.SetFeatureCollection(fc)
 
 	m.BaseWidget.Refresh()
 }
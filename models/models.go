@@ -3,6 +3,8 @@ package models
 import (
 	"github.com/google/uuid"
 
+	"time"
+
 	"github.com/jkulzer/fib-server/sharedModels"
 
 	"gorm.io/gorm"
@@ -10,10 +12,73 @@ import (
 
 type LoginInfo struct {
 	gorm.Model
-	ID         uint `gorm:"primaryKey;autoIncrement"`
-	Token      uuid.UUID
+	ID uint `gorm:"primaryKey;autoIncrement"`
+	// url of the server the login belongs to, a server can have logins of
+	// several accounts
+	ServerUrl string `gorm:"index"`
+	// username of the account, also used to prefill the login when the
+	// session expires
+	Username string
+	// kept in the secret store, not in the database
+	Token uuid.UUID `gorm:"-"`
+	// when the server stops accepting the token, zero if the server didn't say
+	Expiry time.Time
+	// the login used last is the active account on its server
+	LastUsed   time.Time
 	LobbyToken string
 	Role       sharedModels.UserRole
 }
 
+// ServerProfile is a server the user connected to before.
+type ServerProfile struct {
+	gorm.Model
+	Name     string
+	Url      string `gorm:"uniqueIndex"`
+	LastUsed time.Time
+}
+
 var NullUuidString = "00000000-0000-0000-0000-000000000000"
+
+// PendingAction is a request that couldn't reach the server and is sent
+// again once the connection is back.
+type PendingAction struct {
+	gorm.Model
+	Method string
+	// path below the server url, including the lobby token
+	Path      string
+	Body      []byte
+	Action    string
+	ServerUrl string `gorm:"index"`
+	// the login the action is sent with
+	LoginInfoID uint `gorm:"index"`
+	// the action is dropped instead of sent after this, zero if it never is
+	ExpiresAt time.Time
+}
+
+// KnownLobby is a lobby an account joined on this device.
+type KnownLobby struct {
+	gorm.Model
+	LoginInfoID uint `gorm:"index"`
+	LobbyToken  string
+	JoinedAt    time.Time
+}
+
+// Notification is an alert about the game shown to the user, kept for the
+// notification center.
+type Notification struct {
+	gorm.Model
+	Kind       string
+	Title      string
+	Content    string
+	ServerUrl  string
+	LobbyToken string
+	Read       bool
+}
+
+// NotificationSetting turns off the alerts of one kind on this device, all
+// kinds are on without a setting.
+type NotificationSetting struct {
+	gorm.Model
+	Kind     string `gorm:"uniqueIndex"`
+	Disabled bool
+}
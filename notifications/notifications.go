@@ -0,0 +1,81 @@
+// Package notifications keeps the alerts shown to the user and which kinds of
+// alerts they turned off.
+package notifications
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/jkulzer/fib-client/models"
+)
+
+// Kind is the game event a notification is about.
+type Kind string
+
+const (
+	KindPhase    Kind = "phase"
+	KindQuestion Kind = "question"
+	KindCurse    Kind = "curse"
+	KindHand     Kind = "hand"
+	KindTimer    Kind = "timer"
+	// a seeker of the team started a thermometer
+	KindThermometer Kind = "thermometer"
+)
+
+// Kinds are all kinds of notifications, in the order of the settings.
+var Kinds = []Kind{KindPhase, KindTimer, KindQuestion, KindThermometer, KindCurse, KindHand}
+
+// how many notifications are kept, older ones are deleted
+const keep = 200
+
+// Enabled returns whether alerts of the kind are on. Without a readable
+// setting they are.
+func Enabled(db *gorm.DB, kind Kind) bool {
+	var setting models.NotificationSetting
+	result := db.Where("kind = ?", string(kind)).Limit(1).Find(&setting)
+	return result.Error != nil || !setting.Disabled
+}
+
+// SetEnabled turns the alerts of the kind on or off.
+func SetEnabled(db *gorm.DB, kind Kind, enabled bool) error {
+	setting := models.NotificationSetting{Kind: string(kind)}
+	result := db.Where("kind = ?", string(kind)).FirstOrInit(&setting)
+	if result.Error != nil {
+		return result.Error
+	}
+	setting.Disabled = !enabled
+	return db.Save(&setting).Error
+}
+
+// Record adds the notification to the notification center, deleting the
+// oldest ones beyond the ones kept.
+func Record(db *gorm.DB, notification models.Notification) error {
+	result := db.Create(&notification)
+	if result.Error != nil {
+		return result.Error
+	}
+	return db.Unscoped().Where("id NOT IN (?)", db.Model(&models.Notification{}).Select("id").Order("id desc").Limit(keep)).Delete(&models.Notification{}).Error
+}
+
+// List returns the kept notifications, the newest first.
+func List(db *gorm.DB) ([]models.Notification, error) {
+	var notifications []models.Notification
+	result := db.Order("id desc").Find(&notifications)
+	return notifications, result.Error
+}
+
+// Unread returns how many notifications the user didn't see yet.
+func Unread(db *gorm.DB) (int64, error) {
+	var count int64
+	result := db.Model(&models.Notification{}).Where("read = ?", false).Count(&count)
+	return count, result.Error
+}
+
+// MarkAllRead marks all notifications as seen.
+func MarkAllRead(db *gorm.DB) error {
+	return db.Model(&models.Notification{}).Where("read = ?", false).Update("read", true).Error
+}
+
+// Clear deletes all notifications.
+func Clear(db *gorm.DB) error {
+	return db.Unscoped().Where("1 = 1").Delete(&models.Notification{}).Error
+}
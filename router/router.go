@@ -0,0 +1,144 @@
+// Package router decides which screen the app shows. The screen is derived
+// from the login state, the lobby, the role and the game phase, so every way
+// into the app ends up on the same screen for the same state.
+package router
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+// Route is a named screen of the app.
+type Route int
+
+const (
+	Login Route = iota
+	Lobby
+	RoleSelect
+	Readiness
+	RunPhase
+	Narrowing
+	Endgame
+	Finished
+	Profile
+	Accounts
+	AddAccount
+)
+
+func (r Route) String() string {
+	switch r {
+	case Login:
+		return "Login"
+	case Lobby:
+		return "Lobby"
+	case RoleSelect:
+		return "RoleSelect"
+	case Readiness:
+		return "Readiness"
+	case RunPhase:
+		return "RunPhase"
+	case Narrowing:
+		return "Narrowing"
+	case Endgame:
+		return "Endgame"
+	case Finished:
+		return "Finished"
+	case Profile:
+		return "Profile"
+	case Accounts:
+		return "Accounts"
+	case AddAccount:
+		return "AddAccount"
+	default:
+		return "Unknown"
+	}
+}
+
+// State is everything the screen depends on.
+type State struct {
+	LoggedIn   bool
+	LobbyToken string
+	Role       sharedModels.UserRole
+	// only known once the user is in a lobby with a role
+	Phase sharedModels.GamePhase
+}
+
+// CurrentState reads the login state from the database and, if the user
+// has a role in a lobby, fetches the game phase from the server.
+func CurrentState(ctx context.Context, env env.Env) (State, error) {
+	loginInfo, err := helpers.GetAppConfig(env)
+	if errors.Is(err, helpers.ErrNotLoggedIn) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	state := State{
+		LoggedIn:   true,
+		LobbyToken: loginInfo.LobbyToken,
+		Role:       loginInfo.Role,
+		Phase:      sharedModels.PhaseInvalid,
+	}
+	if state.LobbyToken == "" || state.Role == sharedModels.NoRole {
+		return state, nil
+	}
+
+	state.Phase, err = client.NewAPI(env).GetGamePhase(ctx)
+	if err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// Resolve returns the screen to show for the state.
+func Resolve(s State) Route {
+	switch {
+	case !s.LoggedIn:
+		return Login
+	case s.LobbyToken == "":
+		return Lobby
+	case s.Role == sharedModels.NoRole:
+		return RoleSelect
+	}
+
+	switch s.Phase {
+	case sharedModels.PhaseBeforeStart:
+		return Readiness
+	case sharedModels.PhaseRun:
+		return RunPhase
+	case sharedModels.PhaseLocationNarrowing:
+		return Narrowing
+	case sharedModels.PhaseEndgame:
+		return Endgame
+	case sharedModels.PhaseFinished:
+		return Finished
+	default:
+		return Lobby
+	}
+}
+
+// Allowed reports whether the route may be shown in the state, e.g. when
+// navigating back or following a link. Apart from the login, lobby, profile
+// and account screens and changing the role before the game started, only
+// the route the state resolves to is allowed.
+func Allowed(s State, r Route) bool {
+	switch r {
+	case Login:
+		return !s.LoggedIn
+	case Lobby, Profile, Accounts, AddAccount:
+		return s.LoggedIn
+	case RoleSelect:
+		if s.LoggedIn && s.LobbyToken != "" && (s.Role == sharedModels.NoRole || s.Phase == sharedModels.PhaseBeforeStart) {
+			return true
+		}
+	}
+	return r == Resolve(s)
+}
@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+const keySize = 32
+
+var (
+	ErrWrongKey   = errors.New("The secrets can't be decrypted with this key")
+	ErrInvalidKey = errors.New("The key file doesn't hold a valid key")
+)
+
+// FileStore keeps all secrets in one file encrypted with AES-GCM. The file is
+// rewritten on every change.
+type FileStore struct {
+	path string
+	aead cipher.AEAD
+
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewFileStore opens the encrypted file at path, or starts an empty one if it
+// doesn't exist yet. key has to be 32 bytes, see DeviceKey.
+func NewFileStore(path string, key []byte) (*FileStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStore{
+		path:    path,
+		aead:    aead,
+		secrets: make(map[string]string),
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(encrypted) < nonceSize {
+		return nil, ErrWrongKey
+	}
+	plain, err := aead.Open(nil, encrypted[:nonceSize], encrypted[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	err = json.Unmarshal(plain, &s.secrets)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *FileStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[key] = value
+	return s.save()
+}
+
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.secrets[key]; !ok {
+		return nil
+	}
+	delete(s.secrets, key)
+	return s.save()
+}
+
+// save writes the secrets to a temporary file first, so a crash never leaves
+// a half written file behind.
+func (s *FileStore) save() error {
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+	encrypted := s.aead.Seal(nonce, nonce, plain, nil)
+
+	tmpPath := s.path + ".tmp"
+	err = os.WriteFile(tmpPath, encrypted, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// DeviceKey returns the random key stored at path and creates it on first
+// use. The key is a plain file in the app's storage, there is no platform
+// keystore behind it. It only keeps the tokens out of the database, so a copy
+// or a bug report of the database alone doesn't leak them. Anyone who can
+// read the whole storage directory, including its backups, can read the
+// tokens. A key file of the wrong size is reported as ErrInvalidKey instead
+// of being replaced, since a new key can't read the stored secrets.
+func DeviceKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != keySize {
+			return nil, ErrInvalidKey
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, keySize)
+	_, err = io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(path, key, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
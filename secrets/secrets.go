@@ -0,0 +1,19 @@
+// Package secrets stores credentials like session tokens outside of the
+// database, so a copy of the database alone can't be used to take over an
+// account.
+package secrets
+
+import (
+	"errors"
+)
+
+var ErrNotFound = errors.New("Secret not found")
+
+// Store keeps secrets by key.
+type Store interface {
+	// Get returns ErrNotFound if there is no secret for the key.
+	Get(key string) (string, error)
+	Set(key, value string) error
+	// Delete doesn't fail if there is no secret for the key.
+	Delete(key string) error
+}
@@ -0,0 +1,110 @@
+// Package servers manages the saved server profiles. Every server has its
+// own login, so switching the server doesn't log out of the others.
+package servers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/secrets"
+)
+
+var ErrInvalidUrl = errors.New("The server url has to start with http:// or https://")
+
+// NormalizeUrl validates the url and strips trailing slashes, so the same
+// server always ends up in the same profile.
+func NormalizeUrl(serverUrl string) (string, error) {
+	serverUrl = strings.TrimRight(strings.TrimSpace(serverUrl), "/")
+	parsed, err := url.Parse(serverUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", ErrInvalidUrl
+	}
+	return serverUrl, nil
+}
+
+// List returns all saved profiles, the most recently used first.
+func List(db *gorm.DB) ([]models.ServerProfile, error) {
+	var profiles []models.ServerProfile
+	result := db.Order("last_used desc").Find(&profiles)
+	return profiles, result.Error
+}
+
+// Last returns the most recently used profile.
+func Last(db *gorm.DB) (models.ServerProfile, error) {
+	var profile models.ServerProfile
+	result := db.Order("last_used desc").First(&profile)
+	return profile, result.Error
+}
+
+// LastOrDefault returns the most recently used profile and creates the
+// profile of the default server if there is none.
+func LastOrDefault(db *gorm.DB) (models.ServerProfile, error) {
+	profile, err := Last(db)
+	if err == nil {
+		return profile, nil
+	}
+	return Use(db, "Default", env.DefaultUrl)
+}
+
+// Use marks the profile of the server as the most recently used one and
+// creates it if it doesn't exist yet. An empty name keeps the current name.
+func Use(db *gorm.DB, name, serverUrl string) (models.ServerProfile, error) {
+	serverUrl, err := NormalizeUrl(serverUrl)
+	if err != nil {
+		return models.ServerProfile{}, err
+	}
+
+	profile := models.ServerProfile{Name: serverUrl, Url: serverUrl}
+	result := db.Where("url = ?", serverUrl).FirstOrInit(&profile)
+	if result.Error != nil {
+		return profile, result.Error
+	}
+	if name != "" {
+		profile.Name = name
+	}
+	profile.LastUsed = time.Now()
+	result = db.Save(&profile)
+	return profile, result.Error
+}
+
+// Delete removes the profile together with its logins, queued actions and
+// known lobbies.
+func Delete(db *gorm.DB, store secrets.Store, profile models.ServerProfile) error {
+	var logins []models.LoginInfo
+	result := db.Where("server_url = ?", profile.Url).Find(&logins)
+	if result.Error != nil {
+		return result.Error
+	}
+	var loginIDs []uint
+	for _, loginInfo := range logins {
+		err := store.Delete(helpers.TokenKey(loginInfo.ID))
+		if err != nil {
+			return err
+		}
+		loginIDs = append(loginIDs, loginInfo.ID)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("server_url = ?", profile.Url).Delete(&models.LoginInfo{})
+		if result.Error != nil {
+			return result.Error
+		}
+		result = tx.Unscoped().Where("server_url = ?", profile.Url).Delete(&models.PendingAction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(loginIDs) > 0 {
+			result = tx.Unscoped().Where("login_info_id IN ?", loginIDs).Delete(&models.KnownLobby{})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return tx.Unscoped().Delete(&profile).Error
+	})
+}
@@ -0,0 +1,407 @@
+// Package state holds the state of the lobby the user is currently in, so all
+// widgets show the same data without each of them fetching it on their own.
+package state
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/paulmach/orb/geojson"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/workers"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+// Change is the part of the state that changed in a notification.
+type Change int
+
+const (
+	LobbyChanged Change = iota
+	RoleChanged
+	PhaseChanged
+	HistoryChanged
+	HandChanged
+	CursesChanged
+	MapChanged
+	TeamChanged
+)
+
+type listener struct {
+	change Change
+	f      func()
+}
+
+// Store is the state of one lobby. It is kept up to date by a single sync
+// loop listening to the lobby's event stream.
+type Store struct {
+	env    env.Env
+	api    *client.API
+	cancel context.CancelFunc
+
+	mu         sync.RWMutex
+	lobbyToken string
+	role       sharedModels.UserRole
+	phase      sharedModels.GamePhase
+	history    client.History
+	hand       sharedModels.CardList
+	curses     []sharedModels.Card
+	mapData    *geojson.FeatureCollection
+	team       client.SeekerTeam
+	// nil until fetched, the settings of a lobby never change
+	settings *client.LobbySettings
+	// the lobby the settings belong to
+	settingsLobby string
+
+	listenersMu sync.Mutex
+	listeners   map[*listener]bool
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]*Store)
+	// called for every new store
+	createdHooks []func(ctx context.Context, store *Store)
+)
+
+// OnCreated calls f for every store created from now on, before its sync loop
+// starts, so f sees every change. ctx is done once the store is closed. f
+// must not call For.
+func OnCreated(f func(ctx context.Context, store *Store)) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	createdHooks = append(createdHooks, f)
+}
+
+// For returns the store of the lobby the user is currently in, creating it
+// and starting its sync loop on first use.
+func For(env env.Env) (*Store, error) {
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err != nil {
+		return nil, err
+	}
+	key := env.Url + "/lobby/" + loginInfo.LobbyToken
+
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if store, ok := stores[key]; ok {
+		return store, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &Store{
+		env:        env,
+		api:        client.NewAPI(env),
+		cancel:     cancel,
+		lobbyToken: loginInfo.LobbyToken,
+		role:       loginInfo.Role,
+		phase:      sharedModels.PhaseInvalid,
+		listeners:  make(map[*listener]bool),
+	}
+	stores[key] = store
+	for _, f := range createdHooks {
+		f(ctx, store)
+	}
+	workers.Go(ctx, "lobby state sync", store.sync)
+	return store, nil
+}
+
+// CloseAll stops the sync loops of all stores, e.g. when logging out or when
+// the lobby or role changes.
+func CloseAll() {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	for key, store := range stores {
+		store.cancel()
+		delete(stores, key)
+	}
+}
+
+// OnChange calls f every time the given part of the state changes, until ctx
+// is done.
+func (s *Store) OnChange(ctx context.Context, change Change, f func()) {
+	l := &listener{change: change, f: f}
+	s.listenersMu.Lock()
+	s.listeners[l] = true
+	s.listenersMu.Unlock()
+
+	workers.Go(ctx, "lobby state listener", func(ctx context.Context) {
+		<-ctx.Done()
+		s.listenersMu.Lock()
+		delete(s.listeners, l)
+		s.listenersMu.Unlock()
+	})
+}
+
+func (s *Store) notify(change Change) {
+	s.listenersMu.Lock()
+	var toCall []func()
+	for l := range s.listeners {
+		if l.change == change {
+			toCall = append(toCall, l.f)
+		}
+	}
+	s.listenersMu.Unlock()
+
+	for _, f := range toCall {
+		f()
+	}
+}
+
+// set stores value in field and notifies the listeners if it changed.
+func set[T any](s *Store, field *T, value T, change Change) {
+	s.mu.Lock()
+	if reflect.DeepEqual(*field, value) {
+		s.mu.Unlock()
+		return
+	}
+	*field = value
+	s.mu.Unlock()
+	s.notify(change)
+}
+
+// Env returns the env of the server the lobby is on.
+func (s *Store) Env() env.Env {
+	return s.env
+}
+
+func (s *Store) LobbyToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lobbyToken
+}
+
+func (s *Store) Role() sharedModels.UserRole {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.role
+}
+
+func (s *Store) Phase() sharedModels.GamePhase {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.phase
+}
+
+func (s *Store) History() client.History {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.history
+}
+
+func (s *Store) Hand() sharedModels.CardList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hand
+}
+
+func (s *Store) Curses() []sharedModels.Card {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.curses
+}
+
+// Map returns the feature collection drawn on the map, nil until it was
+// fetched for the first time.
+func (s *Store) Map() *geojson.FeatureCollection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mapData
+}
+
+// Team returns the seeker team, only known to seekers.
+func (s *Store) Team() client.SeekerTeam {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.team
+}
+
+// Settings returns the rules of the lobby, fetching them on first use in
+// each lobby.
+func (s *Store) Settings(ctx context.Context) (client.LobbySettings, error) {
+	s.mu.RLock()
+	settings := s.settings
+	settingsLobby := s.settingsLobby
+	lobbyToken := s.lobbyToken
+	s.mu.RUnlock()
+	if settings != nil && settingsLobby == lobbyToken {
+		return *settings, nil
+	}
+
+	fetched, err := s.api.GetLobbySettings(ctx, lobbyToken)
+	if err != nil {
+		return fetched, err
+	}
+	s.mu.Lock()
+	s.settings = &fetched
+	s.settingsLobby = lobbyToken
+	s.mu.Unlock()
+	return fetched, nil
+}
+
+// eventTypes are the events relevant for the role of the player.
+func (s *Store) eventTypes() []client.EventType {
+	eventTypes := []client.EventType{client.EventPhase, client.EventHistory}
+	switch s.Role() {
+	case sharedModels.Hider:
+		eventTypes = append(eventTypes, client.EventHand)
+	case sharedModels.Seeker:
+		eventTypes = append(eventTypes, client.EventCurses, client.EventTeam)
+	}
+	return eventTypes
+}
+
+func (s *Store) sync(ctx context.Context) {
+	events, err := s.api.Subscribe(ctx, s.eventTypes()...)
+	if err != nil {
+		log.Err(err).Msg("failed subscribing to lobby events")
+		return
+	}
+
+	for event := range events {
+		err := s.apply(ctx, event)
+		if err != nil {
+			log.Err(err).Msg("failed applying " + string(event.Type) + " event")
+		}
+	}
+}
+
+func (s *Store) apply(ctx context.Context, event client.Event) error {
+	switch event.Type {
+	case client.EventPhase:
+		var phaseResponse sharedModels.PhaseResponse
+		err := event.Decode(&phaseResponse)
+		if err != nil {
+			return err
+		}
+		set(s, &s.phase, phaseResponse.Phase, PhaseChanged)
+		return s.RefreshMap(ctx)
+	case client.EventHistory:
+		var history client.History
+		err := event.Decode(&history)
+		if err != nil {
+			return err
+		}
+		set(s, &s.history, history, HistoryChanged)
+		// every answered question narrows down the area on the map
+		return s.RefreshMap(ctx)
+	case client.EventHand:
+		var hand sharedModels.CardList
+		err := event.Decode(&hand)
+		if err != nil {
+			return err
+		}
+		set(s, &s.hand, hand, HandChanged)
+	case client.EventCurses:
+		var curses sharedModels.CardList
+		err := event.Decode(&curses)
+		if err != nil {
+			return err
+		}
+		set(s, &s.curses, curses.List, CursesChanged)
+	case client.EventTeam:
+		var team client.SeekerTeam
+		err := event.Decode(&team)
+		if err != nil {
+			return err
+		}
+		set(s, &s.team, team, TeamChanged)
+	}
+	return nil
+}
+
+// RefreshMap fetches the map data from the server.
+func (s *Store) RefreshMap(ctx context.Context) error {
+	mapData, err := s.api.GetMapData(ctx)
+	if err != nil {
+		return err
+	}
+	fc, err := geojson.UnmarshalFeatureCollection(mapData)
+	if err != nil {
+		return err
+	}
+	set(s, &s.mapData, fc, MapChanged)
+	return nil
+}
+
+// RefreshHand fetches the hand of the hider from the server.
+func (s *Store) RefreshHand(ctx context.Context) error {
+	hand, err := s.api.GetHiderHand(ctx)
+	if err != nil {
+		return err
+	}
+	set(s, &s.hand, hand, HandChanged)
+	return nil
+}
+
+// RefreshTeam fetches the seeker team from the server.
+func (s *Store) RefreshTeam(ctx context.Context) error {
+	team, err := s.api.GetSeekerTeam(ctx)
+	if err != nil {
+		return err
+	}
+	set(s, &s.team, team, TeamChanged)
+	return nil
+}
+
+// RefreshHistory fetches the question history from the server.
+func (s *Store) RefreshHistory(ctx context.Context) error {
+	history, err := s.api.GetHistory(ctx)
+	if err != nil {
+		return err
+	}
+	set(s, &s.history, history, HistoryChanged)
+	return nil
+}
+
+// Refresh fetches the whole state from the server instead of waiting for the
+// next event, e.g. right after asking a question.
+func (s *Store) Refresh(ctx context.Context) error {
+	loginInfo, err := helpers.GetAppConfig(s.env)
+	if err != nil {
+		return err
+	}
+	set(s, &s.lobbyToken, loginInfo.LobbyToken, LobbyChanged)
+	set(s, &s.role, loginInfo.Role, RoleChanged)
+
+	phase, err := s.api.GetGamePhase(ctx)
+	if err != nil {
+		return err
+	}
+	set(s, &s.phase, phase, PhaseChanged)
+
+	err = s.RefreshHistory(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch loginInfo.Role {
+	case sharedModels.Hider:
+		err := s.RefreshHand(ctx)
+		if err != nil {
+			return err
+		}
+	case sharedModels.Seeker:
+		curses, err := s.api.GetCurses(ctx)
+		if err != nil {
+			return err
+		}
+		set(s, &s.curses, curses, CursesChanged)
+		// servers without seeker teams don't know the team, the game works
+		// without it
+		err = s.RefreshTeam(ctx)
+		if err != nil {
+			log.Debug().Msg("seeker team not available: " + fmt.Sprint(err))
+		}
+	default:
+		log.Debug().Msg("no role specific state for role " + fmt.Sprint(loginInfo.Role))
+	}
+
+	return s.RefreshMap(ctx)
+}
@@ -0,0 +1,121 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/router"
+	"github.com/jkulzer/fib-client/state"
+)
+
+type AccountsWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewAccountsWidget lists the accounts logged in on the current server and
+// switches between them. Every account keeps its own session, lobby and role.
+func NewAccountsWidget(env env.Env, parentWindow fyne.Window) *AccountsWidget {
+	w := &AccountsWidget{}
+	w.ExtendBaseWidget(w)
+
+	backButton := widget.NewButton("Back", func() {
+		RouterFor(parentWindow).Back()
+	})
+	addButton := widget.NewButton("Add account", func() {
+		RouterFor(parentWindow).Navigate(router.AddAccount)
+	})
+	top := container.NewHBox(backButton, addButton)
+
+	list := container.NewVBox(widget.NewLabelWithStyle("Accounts on "+env.Url, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	w.content = container.NewBorder(top, nil, nil, nil, container.NewVScroll(list))
+
+	accounts, err := helpers.ListAccounts(env)
+	if err != nil {
+		log.Err(err).Msg("failed listing accounts")
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	for i, account := range accounts {
+		name := account.Username
+		if name == "" {
+			name = "Unnamed account"
+		}
+		if account.LobbyToken != "" {
+			name += " (lobby " + account.LobbyToken + ")"
+		}
+
+		useButton := widget.NewButton("Use", func() {
+			switchAccount(env, parentWindow, account)
+		})
+		if i == 0 {
+			name += ", active"
+			useButton.Disable()
+		}
+		removeButton := widget.NewButton("Remove", func() {
+			confirmRemoveAccount(env, parentWindow, account)
+		})
+		list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(useButton, removeButton), widget.NewLabel(name)))
+	}
+
+	return w
+}
+
+func (w *AccountsWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+// newAccountsButton opens the account list.
+func newAccountsButton(parentWindow fyne.Window) *widget.Button {
+	return widget.NewButton("Accounts", func() {
+		RouterFor(parentWindow).Navigate(router.Accounts)
+	})
+}
+
+func switchAccount(env env.Env, parentWindow fyne.Window, account models.LoginInfo) {
+	err := helpers.UseAccount(env, account)
+	if err != nil {
+		log.Err(err).Msg("failed switching account")
+		dialog.ShowError(err, parentWindow)
+		return
+	}
+	log.Info().Msg("switched to account " + account.Username)
+	// the lobby state belongs to the previous account
+	state.CloseAll()
+	RouterFor(parentWindow).Sync()
+}
+
+// confirmRemoveAccount logs the account out of the server and forgets it on
+// this device. The other accounts stay logged in.
+func confirmRemoveAccount(env env.Env, parentWindow fyne.Window, account models.LoginInfo) {
+	dialog.ShowConfirm("Remove account", "Log "+account.Username+" out and remove it from this device?", func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		go func() {
+			err := client.NewAPI(env, client.WithTokenSource(client.StaticTokenSource(account))).Logout(context.Background())
+			if err != nil {
+				log.Warn().Msg("failed ending session on the server: " + fmt.Sprint(err))
+			}
+			err = helpers.RemoveAccount(env, account)
+			if err != nil {
+				log.Err(err).Msg("failed removing account")
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			state.CloseAll()
+			RouterFor(parentWindow).Navigate(router.Accounts)
+		}()
+	}, parentWindow)
+}
@@ -1,61 +1,191 @@
 package widgets
 
 import (
-	"errors"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/validation"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/helpers"
-	"github.com/jkulzer/fib-client/models"
+)
+
+// authStep is the step of a registration or login in flight.
+type authStep int
 
-	"github.com/jkulzer/fib-server/sharedModels"
+const (
+	authIdle authStep = iota
+	authConnecting
+	authRegistering
+	authLoggingIn
 )
 
-type RegisterWidget struct {
-	widget.BaseWidget
-	content *fyne.Container
-	form    *widget.Form
+func (s authStep) String() string {
+	switch s {
+	case authConnecting:
+		return "Connecting to server..."
+	case authRegistering:
+		return "Registering..."
+	case authLoggingIn:
+		return "Logging in..."
+	default:
+		return ""
+	}
 }
 
-func NewRegisterWidget(env env.Env, parentWindow fyne.Window) *RegisterWidget {
-	w := &RegisterWidget{}
-	w.ExtendBaseWidget(w)
+// authFlow runs one registration or login at a time. A registration only
+// logs in once it succeeded, and every failure ends the flow with the error
+// of the step that failed.
+type authFlow struct {
+	env env.Env
+
+	mu   sync.Mutex
+	step authStep
+	// called on every step, authIdle means the flow is over
+	onStep func(authStep)
+}
+
+// start runs the flow in the background. It does nothing while another
+// registration or login is still in flight.
+func (f *authFlow) start(register bool, username, password string, done func(error)) {
+	f.mu.Lock()
+	if f.step != authIdle {
+		f.mu.Unlock()
+		return
+	}
+	// taken before unlocking, so a second submit can't start another flow
+	f.step = authConnecting
+	onStep := f.onStep
+	f.mu.Unlock()
+	if onStep != nil {
+		onStep(authConnecting)
+	}
+
+	go func() {
+		err := f.run(register, username, password)
+		f.setStep(authIdle)
+		done(err)
+	}()
+}
+
+func (f *authFlow) run(register bool, username, password string) error {
+	api := client.NewAPI(f.env)
+	_, err := api.Probe(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if register {
+		f.setStep(authRegistering)
+		err := api.Register(context.Background(), username, password)
+		if err != nil {
+			return err
+		}
+		log.Info().Msg("user registered")
+	}
+
+	f.setStep(authLoggingIn)
+	sessionStruct, err := api.Login(context.Background(), username, password)
+	if err != nil && register {
+		return fmt.Errorf("Registered, but logging in failed: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+	log.Info().Msg("user logged in with a session which expires at " + fmt.Sprint(sessionStruct.Expiry))
 
+	err = helpers.SaveSession(f.env, username, sessionStruct)
+	if err != nil {
+		log.Warn().Msg("error writing user config to DB with error " + fmt.Sprint(err))
+		return err
+	}
+	log.Info().Msg("wrote user config to DB")
+	return nil
+}
+
+func (f *authFlow) setStep(step authStep) {
+	f.mu.Lock()
+	f.step = step
+	onStep := f.onStep
+	f.mu.Unlock()
+	if onStep != nil {
+		onStep(step)
+	}
+}
+
+// newAuthForm builds the form shared by the register and login tabs. The
+// form is disabled while its request is in flight.
+func newAuthForm(env env.Env, parentWindow fyne.Window, register bool) *widget.Form {
 	usernameEntry := widget.NewEntry()
 	usernameEntry.SetPlaceHolder("AzureDiamond")
-	usernameEntry.Validator = validation.NewRegexp("^.{4,32}$", "Username must be at least 4 or at most 32 characters long")
-
 	passwordEntry := widget.NewPasswordEntry()
 	passwordEntry.SetPlaceHolder("hunter2")
-	passwordEntry.Validator = validation.NewRegexp("^.{8,32}$", "Password must be at least 8 or at most 32 characters long")
+	if register {
+		usernameEntry.Validator = validation.NewRegexp("^.{4,32}$", "Username must be at least 4 or at most 32 characters long")
+		passwordEntry.Validator = validation.NewRegexp("^.{8,32}$", "Password must be at least 8 or at most 32 characters long")
+	} else {
+		usernameEntry.Validator = validation.NewRegexp("^.{4,32}$", "Username is at least 4 or at most 32 characters long")
+		passwordEntry.Validator = validation.NewRegexp("^.{8,32}$", "Password is at least 8 or at most 32 characters long")
+	}
 
+	statusLabel := widget.NewLabel("")
 	form := &widget.Form{
 		Items: []*widget.FormItem{ // we can specify items in the constructor
 			{Text: "Username", Widget: usernameEntry},
 			{Text: "Password", Widget: passwordEntry},
+			{Text: "", Widget: statusLabel},
 		},
-		OnSubmit: func() { // optional, handle form submission
-			go func() {
-				registerOnServer(env, usernameEntry.Text, passwordEntry.Text, parentWindow)
-				loginOnServer(env, usernameEntry.Text, passwordEntry.Text, parentWindow)
-			}()
+		SubmitText: "Login",
+	}
+	if register {
+		form.SubmitText = "Register"
+	}
+
+	flow := &authFlow{
+		env: env,
+		onStep: func(step authStep) {
+			statusLabel.SetText(step.String())
+			if step == authIdle {
+				form.Enable()
+			} else {
+				form.Disable()
+			}
 		},
-		SubmitText: "Register",
 	}
+	form.OnSubmit = func() {
+		flow.start(register, usernameEntry.Text, passwordEntry.Text, func(err error) {
+			if err != nil {
+				log.Warn().Msg("failed authenticating: " + fmt.Sprint(err))
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			RouterFor(parentWindow).Sync()
+		})
+	}
+	return form
+}
 
+type RegisterWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+	form    *widget.Form
+}
+
+func NewRegisterWidget(env env.Env, parentWindow fyne.Window) *RegisterWidget {
+	w := &RegisterWidget{}
+	w.ExtendBaseWidget(w)
+
+	w.form = newAuthForm(env, parentWindow, true)
 	w.content = container.NewVBox(
-		form,
+		w.form,
 	)
 	return w
 }
@@ -64,36 +194,6 @@ func (w *RegisterWidget) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(w.content)
 }
 
-func registerOnServer(env env.Env, username, password string, parentWindow fyne.Window) {
-	loginInfo := sharedModels.LoginInfo{
-		Username: username,
-		Password: password,
-	}
-	loginInfoJson, err := json.Marshal(loginInfo)
-	if err != nil {
-		log.Warn().Msg("failed to marshall login info json")
-	}
-
-	bodyReader := bytes.NewReader(loginInfoJson)
-	res, err := http.Post(env.Url+"/register", "application/json", bodyReader)
-	if err != nil {
-		log.Warn().Msg("couldn't make request" + fmt.Sprint(err))
-		dialog.ShowError(err, parentWindow)
-	} else {
-		if res.StatusCode == http.StatusCreated {
-			log.Info().Msg("user registered")
-			dialog.ShowInformation("Registration", "Registration successful!", parentWindow)
-		} else if res.StatusCode == http.StatusBadRequest {
-			log.Warn().Msg("user already exists")
-			error := errors.New("User already exists")
-			dialog.ShowError(error, parentWindow)
-		} else {
-			error := errors.New("failed registering with code " + fmt.Sprint(res.StatusCode))
-			dialog.ShowError(error, parentWindow)
-		}
-	}
-}
-
 type LoginWidget struct {
 	widget.BaseWidget
 	content *fyne.Container
@@ -104,29 +204,9 @@ func NewLoginWidget(env env.Env, parentWindow fyne.Window) *LoginWidget {
 	w := &LoginWidget{}
 	w.ExtendBaseWidget(w)
 
-	usernameEntry := widget.NewEntry()
-	usernameEntry.SetPlaceHolder("AzureDiamond")
-	usernameEntry.Validator = validation.NewRegexp("^.{4,32}$", "Username is at least 4 or at most 32 characters long")
-
-	passwordEntry := widget.NewPasswordEntry()
-	passwordEntry.SetPlaceHolder("hunter2")
-	passwordEntry.Validator = validation.NewRegexp("^.{8,32}$", "Password is at least 8 or at most 32 characters long")
-
-	form := &widget.Form{
-		Items: []*widget.FormItem{ // we can specify items in the constructor
-			{Text: "Username", Widget: usernameEntry},
-			{Text: "Password", Widget: passwordEntry},
-		},
-		OnSubmit: func() { // optional, handle form submission
-			go func() {
-				loginOnServer(env, usernameEntry.Text, passwordEntry.Text, parentWindow)
-			}()
-		},
-		SubmitText: "Login",
-	}
-
+	w.form = newAuthForm(env, parentWindow, false)
 	w.content = container.NewVBox(
-		form,
+		w.form,
 	)
 	return w
 }
@@ -135,68 +215,21 @@ func (w *LoginWidget) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(w.content)
 }
 
-func loginOnServer(env env.Env, username, password string, parentWindow fyne.Window) {
-	loginInfo := sharedModels.LoginInfo{
-		Username: username,
-		Password: password,
-	}
-	loginInfoJson, err := json.Marshal(loginInfo)
-	if err != nil {
-		log.Warn().Msg("failed to marshall login info json")
-	}
-
-	bodyReader := bytes.NewReader(loginInfoJson)
-	res, err := http.Post(env.Url+"/login", "application/json", bodyReader)
-	if err != nil {
-		log.Warn().Msg("couldn't make request" + fmt.Sprint(err))
-		dialog.ShowError(err, parentWindow)
-	} else {
-		switch res.StatusCode {
-		case http.StatusOK, http.StatusCreated:
-			httpResponse, err := helpers.ReadHttpResponse(res.Body)
-			if err != nil {
-				log.Warn().Msg("failed to read session token http response: " + fmt.Sprint(err))
-			}
-			var sessionStruct sharedModels.SessionToken
-			err = json.Unmarshal(httpResponse, &sessionStruct)
-			if err != nil {
-				log.Warn().Msg("failed to unmarshal session token struct on login: " + fmt.Sprint(err))
-			}
-			log.Info().Msg("user logged in with session token " + fmt.Sprint(sessionStruct.Token) + " which expires at " + fmt.Sprint(sessionStruct.Expiry))
-
-			userName := models.LoginInfo{
-				ID:    1,
-				Token: sessionStruct.Token,
-			}
-			// tries to create the user in the db
-			result := env.DB.Save(&userName)
-			if result.Error != nil {
-				log.Warn().Msg("error writing user config to DB with error " + fmt.Sprint(result.Error))
-			} else {
-				log.Info().Msg("wrote user config to DB")
-				// loginInfo, _ := helpers.GetAppConfig(env, parentWindow)
-				// fmt.Println(loginInfo)
-				parentWindow.SetContent(NewLobbyWidget(env, parentWindow))
-			}
-		case http.StatusForbidden:
-		case http.StatusBadRequest:
-			message := "Wrong Password"
-			log.Info().Msg(message)
-			error := errors.New(message)
-			dialog.ShowError(error, parentWindow)
-		default:
-			dialog.ShowError(errors.New(fmt.Sprint(res.StatusCode)), parentWindow)
-		}
-	}
-
+// logIn logs in without any form, e.g. after the session expired.
+func logIn(env env.Env, username, password string) error {
+	flow := &authFlow{env: env}
+	return flow.run(false, username, password)
 }
 
-func GetLoginRegisterTabs(env env.Env, parentWindow fyne.Window) *container.AppTabs {
+// GetLoginRegisterTabs shows the login and register forms for the selected
+// server.
+func GetLoginRegisterTabs(env env.Env, parentWindow fyne.Window) fyne.CanvasObject {
 	register := NewRegisterWidget(env, parentWindow)
 	login := NewLoginWidget(env, parentWindow)
 
-	return container.NewAppTabs(
+	tabs := container.NewAppTabs(
 		container.NewTabItem("Register", register),
 		container.NewTabItem("Login", login),
 	)
+	return container.NewBorder(NewServerSelectWidget(env, parentWindow), nil, nil, nil, tabs)
 }
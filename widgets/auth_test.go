@@ -0,0 +1,212 @@
+package widgets
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/secrets"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+// memorySecrets is a secrets.Store without a file.
+type memorySecrets struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+func (s *memorySecrets) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *memorySecrets) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[key] = value
+	return nil
+}
+
+func (s *memorySecrets) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.secrets, key)
+	return nil
+}
+
+// fakeServer answers /version, /register and /login, counting the requests
+// per path. Handlers set for a path replace the default answer.
+type fakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]int
+	handlers map[string]http.HandlerFunc
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{
+		requests: make(map[string]int),
+		handlers: make(map[string]http.HandlerFunc),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		handler, ok := s.handlers[r.URL.Path]
+		s.mu.Unlock()
+		if ok {
+			handler(w, r)
+			return
+		}
+		switch r.URL.Path {
+		case "/version":
+			json.NewEncoder(w).Encode(client.ServerInfo{Version: "test", APIVersion: client.APIVersion})
+		case "/register":
+			w.WriteHeader(http.StatusOK)
+		case "/login":
+			json.NewEncoder(w).Encode(sharedModels.SessionToken{Token: uuid.New(), Expiry: time.Now().Add(time.Hour)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) handle(path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[path] = handler
+}
+
+func (s *fakeServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+func newTestEnv(t *testing.T, serverUrl string) env.Env {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&models.LoginInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env.Env{
+		DB:      db,
+		Url:     serverUrl,
+		Secrets: &memorySecrets{secrets: make(map[string]string)},
+	}
+}
+
+func TestAuthFlowLogsInAfterRegistering(t *testing.T) {
+	server := newFakeServer(t)
+	flow := &authFlow{env: newTestEnv(t, server.URL)}
+
+	err := flow.run(true, "AzureDiamond", "hunter22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.count("/register") != 1 || server.count("/login") != 1 {
+		t.Errorf("got %d registrations and %d logins, want one each", server.count("/register"), server.count("/login"))
+	}
+}
+
+func TestAuthFlowDoesNotLogInIfRegisteringFails(t *testing.T) {
+	server := newFakeServer(t)
+	server.handle("/register", status(http.StatusConflict))
+	flow := &authFlow{env: newTestEnv(t, server.URL)}
+
+	err := flow.run(true, "AzureDiamond", "hunter22")
+	if !errors.Is(err, client.ErrUserExists) {
+		t.Errorf("got error %v, want %v", err, client.ErrUserExists)
+	}
+	if server.count("/login") != 0 {
+		t.Errorf("logged in %d times after a failed registration", server.count("/login"))
+	}
+}
+
+func TestAuthFlowLoginErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusForbidden, client.ErrLoginForbidden},
+		{http.StatusLocked, client.ErrAccountLocked},
+		{http.StatusUnauthorized, client.ErrWrongPassword},
+		{http.StatusNotFound, client.ErrUnknownUser},
+	}
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			server := newFakeServer(t)
+			server.handle("/login", status(test.status))
+			flow := &authFlow{env: newTestEnv(t, server.URL)}
+
+			err := flow.run(false, "AzureDiamond", "hunter22")
+			if !errors.Is(err, test.want) {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+			for _, other := range tests {
+				if other.want != test.want && errors.Is(err, other.want) {
+					t.Errorf("error %v is also %v", err, other.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthFlowIgnoresSubmitWhileInFlight(t *testing.T) {
+	server := newFakeServer(t)
+	release := make(chan struct{})
+	server.handle("/login", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		json.NewEncoder(w).Encode(sharedModels.SessionToken{Token: uuid.New()})
+	})
+	flow := &authFlow{env: newTestEnv(t, server.URL)}
+
+	done := make(chan error, 2)
+	for range 2 {
+		flow.start(false, "AzureDiamond", "hunter22", func(err error) {
+			done <- err
+		})
+	}
+	close(release)
+
+	err := <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+		t.Error("the second submit ran a flow of its own")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if server.count("/login") != 1 {
+		t.Errorf("got %d logins, want 1", server.count("/login"))
+	}
+}
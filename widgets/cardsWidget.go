@@ -1,6 +1,7 @@
 package widgets
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"image/color"
@@ -16,23 +17,54 @@ import (
 
 	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/notifications"
+	"github.com/jkulzer/fib-client/state"
 	"github.com/jkulzer/fib-server/sharedModels"
 )
 
 type CardsWidget struct {
 	widget.BaseWidget
 	content      *fyne.Container
+	drawsContent *fyne.Container
+	handContent  *fyne.Container
 	env          env.Env
+	store        *state.Store
 	parentWindow fyne.Window
+	// the settings of a lobby never change, so this is only looked up once
+	maxHandSize int
 }
 
-func NewCardsWidget(env env.Env, parentWindow fyne.Window) *CardsWidget {
+// NewCardsWidget shows the draws of the hider and their hand. The hand is
+// rendered from the lobby state, the draws are fetched again whenever a
+// question was answered, which is what earns the hider new draws.
+func NewCardsWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *CardsWidget {
 	w := &CardsWidget{
 		env:          env,
 		parentWindow: parentWindow,
+		drawsContent: container.NewVBox(),
+		handContent:  container.NewVBox(),
 	}
 	w.ExtendBaseWidget(w)
-	w.content = container.NewVBox()
+	w.content = container.NewVBox(w.drawsContent, w.handContent)
+
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state")
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	w.store = store
+	w.maxHandSize = lobbySettings(ctx, env).MaxHandSize
+	w.store.OnChange(ctx, state.HandChanged, func() {
+		w.showHand()
+		w.BaseWidget.Refresh()
+	})
+	w.store.OnChange(ctx, state.HistoryChanged, func() {
+		w.refreshDraws()
+		w.BaseWidget.Refresh()
+	})
+	w.refreshDraws()
+	w.showHand()
 
 	return w
 }
@@ -41,30 +73,32 @@ func (w *CardsWidget) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(container.NewScroll(w.content))
 }
 
-func (w *CardsWidget) SetContent() error {
-	w.content.RemoveAll()
-	log.Info().Msg("refreshing card widget")
-	cardActions, err := client.GetCardActions(w.env, w.parentWindow)
+// refreshDraws fetches the draws the hider may make and the one in progress.
+func (w *CardsWidget) refreshDraws() error {
+	log.Info().Msg("refreshing card draws")
+	api := client.NewAPI(w.env)
+	cardActions, err := api.GetCardActions(context.Background())
 	if err != nil {
-		dialog.ShowError(err, w.parentWindow)
+		showRequestError(err, w.parentWindow)
 		return err
 	}
 	log.Debug().Msg(fmt.Sprint("card draws: ", cardActions))
-	draw, err := client.GetDraw(w.env, w.parentWindow)
+	draw, err := api.GetDraw(context.Background())
 	if err != nil {
 		log.Err(err).Msg("failed getting draw")
-		dialog.ShowError(err, w.parentWindow)
+		showRequestError(err, w.parentWindow)
 		return err
 	}
+	w.drawsContent.RemoveAll()
 	if len(draw.Cards) > 0 {
-		w.content.Add(
+		w.drawsContent.Add(
 			widget.NewButton("Resume in progress draw", func() {
 				cardSelectDialog(w.env, w.parentWindow, w)
 			}),
 		)
 	}
 	if len(cardActions.Draws) == 0 {
-		w.content.Add(widget.NewLabel("no remaining draws"))
+		w.drawsContent.Add(widget.NewLabel("no remaining draws"))
 	} else {
 		drawContainer := container.NewVBox()
 		for _, cardAction := range cardActions.Draws {
@@ -74,14 +108,14 @@ func (w *CardsWidget) SetContent() error {
 					widget.NewLabel("Draw "+fmt.Sprint(cardAction.CardsToDraw)+" cards and pick "+fmt.Sprint(cardAction.CardsToPick)),
 					widget.NewButton("Draw!", func() {
 						log.Debug().Msg("use card draw with ID " + fmt.Sprint(cardAction.DrawID))
-						err := client.DrawCards(w.env, w.parentWindow, cardAction.DrawID)
+						err := api.DrawCards(context.Background(), cardAction.DrawID)
 						if errors.Is(err, client.ErrAlreadyDrewCards) {
 							dialog.ShowInformation("Card drawing", "You need to pick cards from your previous draw before you can draw new cards", w.parentWindow)
 							return
 						}
 						if err != nil {
 							log.Err(err).Msg("failed drawing cards")
-							dialog.ShowError(err, w.parentWindow)
+							showRequestError(err, w.parentWindow)
 							return
 						}
 						cardSelectDialog(w.env, w.parentWindow, w)
@@ -89,25 +123,40 @@ func (w *CardsWidget) SetContent() error {
 				),
 			)
 		}
-		w.content.Add(drawContainer)
+		w.drawsContent.Add(drawContainer)
 	}
-	hiderHand, err := client.GetHiderHand(w.env, w.parentWindow)
-	if err != nil {
-		log.Err(err).Msg("failed getting hider hand")
-		dialog.ShowError(err, w.parentWindow)
+	w.drawsContent.Refresh()
+	return nil
+}
+
+// showHand renders the hand held in the lobby state.
+func (w *CardsWidget) showHand() {
+	var hiderHand sharedModels.CardList
+	if w.store != nil {
+		hiderHand = w.store.Hand()
 	}
 	cardGrid := container.NewGridWithRows(2)
 	for _, handCard := range hiderHand.List {
 		cardGrid.Add(NewCardWidget(handCard, PlayCardWidget, nil, 0, w.env, w.parentWindow, w))
 	}
-	w.content.Add(widget.NewLabel("Your hand:"))
-	w.content.Add(container.NewHScroll(cardGrid))
-	w.content.Refresh()
-	return nil
+	w.handContent.RemoveAll()
+	w.handContent.Add(widget.NewLabel("Your hand (" + fmt.Sprint(len(hiderHand.List)) + " of " + fmt.Sprint(w.maxHandSize) + " cards):"))
+	w.handContent.Add(container.NewHScroll(cardGrid))
+	w.handContent.Refresh()
 }
 
+// Refresh fetches the hand from the server right away, e.g. after playing or
+// discarding a card, and redraws the widget.
 func (w *CardsWidget) Refresh() {
-	w.SetContent()
+	if w.store != nil {
+		err := w.store.RefreshHand(context.Background())
+		if err != nil {
+			log.Err(err).Msg("failed getting hider hand")
+			showRequestError(err, w.parentWindow)
+		}
+	}
+	w.refreshDraws()
+	w.showHand()
 	w.BaseWidget.Refresh()
 }
 
@@ -129,6 +178,7 @@ type CardWidget struct {
 	parentWindow     fyne.Window
 	widgetType       CardWidgetType
 	cardSelectWidget *CardSelectWidget
+	cardsWidget      *CardsWidget
 	card             sharedModels.Card
 }
 
@@ -146,6 +196,7 @@ func NewCardWidget(card sharedModels.Card, widgetType CardWidgetType, cardSelect
 		selectedText:     widget.NewLabel("selected"),
 		card:             card,
 		cardSelectWidget: cardSelectWidget,
+		cardsWidget:      cardsWidget,
 		widgetType:       widgetType,
 		env:              env,
 		parentWindow:     parentWindow,
@@ -176,10 +227,9 @@ func NewCardWidget(card sharedModels.Card, widgetType CardWidgetType, cardSelect
 		widget.NewButton("Discard card", func() {
 			dialog.ShowConfirm("Discard card", "Are you sure you want to discard this card?", func(confirmed bool) {
 				if confirmed {
-					err := client.DiscardCard(env, parentWindow, w.card.IDInDB)
+					err := client.NewAPI(env).DiscardCard(context.Background(), w.card.IDInDB)
 					if err != nil {
-						log.Err(err).Msg("failed discarding card")
-						dialog.ShowError(err, parentWindow)
+						showRequestError(err, parentWindow)
 					}
 					cardsWidget.Refresh()
 				}
@@ -219,9 +269,15 @@ func (w *CardWidget) Tapped(*fyne.PointEvent) {
 		w.BaseWidget.Refresh()
 	case PlayCardWidget:
 		dialog.ShowConfirm("Card", "Are you sure you want to play this card?", func(confirmed bool) {
-			if confirmed {
-				client.PlayCard(w.env, w.parentWindow, w.card.IDInDB)
+			if !confirmed {
+				return
 			}
+			err := client.NewAPI(w.env).PlayCard(context.Background(), w.card.IDInDB)
+			if err != nil {
+				showRequestError(err, w.parentWindow)
+				return
+			}
+			w.cardsWidget.Refresh()
 		}, w.parentWindow)
 	}
 }
@@ -246,20 +302,21 @@ func NewCardSelectWidget(env env.Env, parentWindow fyne.Window, cardDrawDialog *
 		cardsWidget:    cardsWidget,
 	}
 	w.ExtendBaseWidget(w)
-	draw, err := client.GetDraw(w.env, w.parentWindow)
+	draw, err := client.NewAPI(w.env).GetDraw(context.Background())
 	if err != nil {
 		log.Err(err).Msg("failed getting draw")
-		dialog.ShowError(err, w.parentWindow)
+		showRequestError(err, w.parentWindow)
 		return w
 	}
 	w.draw = draw
 	w.pickButton = widget.NewButton("Pick cards", func() {
 		fmt.Println("picking cards:")
 		fmt.Println(w.selectedCards)
-		err := client.PickCards(env, parentWindow, w.selectedCards)
+		err := client.NewAPI(env).PickCards(context.Background(), w.selectedCards)
 		if err != nil {
-			log.Err(err).Msg("failed picking cards")
-			dialog.ShowError(err, parentWindow)
+			showRequestError(err, parentWindow)
+		} else {
+			madeOwnChange(notifications.KindHand)
 		}
 		w.cardsWidget.Refresh()
 	})
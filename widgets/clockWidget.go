@@ -0,0 +1,114 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/workers"
+)
+
+// how often the offset of the server's clock is estimated again, clocks drift
+// slowly so this can be rare
+const clockSyncInterval = 10 * time.Minute
+
+// syncClock keeps the estimate of the server's clock up to date until ctx is
+// done.
+func syncClock(ctx context.Context, env env.Env) {
+	api := client.NewAPI(env)
+	ticker := time.NewTicker(clockSyncInterval)
+	defer ticker.Stop()
+	for {
+		estimate, err := api.SyncClock(ctx)
+		if err != nil {
+			// older servers don't tell their time, their timestamps are used as is
+			log.Debug().Msg("failed syncing clock with server: " + fmt.Sprint(err))
+		} else {
+			log.Info().Msg("server clock is " + estimate.Offset.String() + " ahead, round trip " + estimate.RoundTrip.String())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ClockWidget shows how far the server's clock is off from the device's, to
+// debug countdowns that don't match between players.
+type ClockWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+	details *widget.Label
+	api     *client.API
+}
+
+func NewClockWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *ClockWidget {
+	w := &ClockWidget{api: client.NewAPI(env)}
+	w.ExtendBaseWidget(w)
+	w.details = widget.NewLabel("")
+
+	syncButton := widget.NewButton("Sync now", nil)
+	syncButton.OnTapped = func() {
+		syncButton.Disable()
+		go func() {
+			defer syncButton.Enable()
+			_, err := w.api.SyncClock(ctx)
+			if err != nil {
+				showRequestError(err, parentWindow)
+			}
+			w.update()
+		}()
+	}
+	w.content = container.NewBorder(nil, syncButton, nil, nil, container.NewScroll(w.details))
+
+	workers.Go(ctx, "clock details", func(ctx context.Context) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			w.update()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	return w
+}
+
+func (w *ClockWidget) update() {
+	estimate, ok := w.api.ClockEstimate()
+	if !ok {
+		w.details.SetText("Not synced with the server yet, its timestamps are used as they are")
+		return
+	}
+	text := fmt.Sprintf("Server clock offset: %s\nRound trip: %s\nLast synced %s ago\n\nSamples:",
+		estimate.Offset, estimate.RoundTrip, time.Since(estimate.SyncedAt).Truncate(time.Second))
+	for _, sample := range estimate.Samples {
+		text += fmt.Sprintf("\noffset %s, round trip %s", sample.Offset, sample.RoundTrip)
+	}
+	w.details.SetText(text)
+}
+
+func (w *ClockWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+func showClockDialog(env env.Env, parentWindow fyne.Window) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clockDialog := dialog.NewCustom("Server clock", "Close", NewClockWidget(ctx, env, parentWindow), parentWindow)
+	clockDialog.SetOnClosed(cancel)
+	clockDialog.Resize(fyne.NewSize(400, 500))
+	clockDialog.Show()
+}
@@ -0,0 +1,307 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/notifications"
+	"github.com/jkulzer/fib-client/workers"
+)
+
+const (
+	// fastest redraw, enough for a smooth hundredths display
+	minCountdownInterval = 50 * time.Millisecond
+	// redraw interval while the countdown isn't shown
+	hiddenCountdownInterval = time.Second
+	// check interval for the thresholds while the app is in the background,
+	// nothing is drawn then
+	backgroundCountdownInterval = 5 * time.Second
+)
+
+// CountdownMode tells whether a Countdown shows the time left until its target
+// or the time passed since it.
+type CountdownMode int
+
+const (
+	CountDown CountdownMode = iota
+	CountUp
+)
+
+// CountdownThreshold changes a Countdown once the time left, or the time
+// passed when counting up, reaches At.
+type CountdownThreshold struct {
+	At time.Duration
+	// color of the text from then on, nil keeps the current one
+	Color color.Color
+	// called once when the threshold is reached, not for thresholds already
+	// reached when the countdown starts
+	OnReached func()
+}
+
+// Countdown shows the time until or since a target time. It redraws often
+// only while it is shown and stops drawing while the app is in the
+// background, so it doesn't drain the battery.
+type Countdown struct {
+	widget.BaseWidget
+	text *canvas.Text
+
+	mode      CountdownMode
+	precision time.Duration
+
+	mu         sync.Mutex
+	target     time.Time
+	thresholds []CountdownThreshold
+	reached    []bool
+	format     func(time.Duration) string
+	// shown once a countdown ran out
+	finishedText string
+	onFinished   func()
+	finished     bool
+	// whether the thresholds were checked since the target was set
+	checked bool
+	// wakes the worker when the target changes
+	targetChanged chan struct{}
+}
+
+// NewCountdown creates a countdown showing the time with the given precision,
+// e.g. time.Second or 10*time.Millisecond. It runs until ctx is done and shows
+// a placeholder until SetTarget is called.
+func NewCountdown(ctx context.Context, name string, mode CountdownMode, precision time.Duration) *Countdown {
+	c := &Countdown{
+		mode:          mode,
+		precision:     precision,
+		targetChanged: make(chan struct{}, 1),
+	}
+	c.ExtendBaseWidget(c)
+	c.text = canvas.NewText("Countdown initializing", theme.ForegroundColor())
+	c.text.Alignment = fyne.TextAlignCenter
+	c.text.TextStyle = fyne.TextStyle{Bold: true}
+	c.format = func(d time.Duration) string {
+		return formatTimer(d, c.precision)
+	}
+
+	workers.Go(ctx, name, c.run)
+	return c
+}
+
+// SetTarget sets the time counted down to or up from and starts over. The
+// zero time stops updating the countdown.
+func (c *Countdown) SetTarget(target time.Time) {
+	c.mu.Lock()
+	c.target = target
+	c.finished = false
+	c.checked = false
+	c.reached = make([]bool, len(c.thresholds))
+	c.mu.Unlock()
+	select {
+	case c.targetChanged <- struct{}{}:
+	default:
+	}
+}
+
+// AddThreshold adds a threshold. Thresholds are best added before the target
+// is set.
+func (c *Countdown) AddThreshold(threshold CountdownThreshold) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.thresholds = append(c.thresholds, threshold)
+	c.reached = append(c.reached, false)
+}
+
+// SetFinished sets the text shown once a countdown ran out and a function
+// called then.
+func (c *Countdown) SetFinished(text string, onFinished func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finishedText = text
+	c.onFinished = onFinished
+}
+
+// SetFormat replaces how the time is written, the duration is never negative
+// when counting down.
+func (c *Countdown) SetFormat(format func(time.Duration) string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.format = format
+}
+
+func (c *Countdown) SetTextSize(size float32) {
+	c.text.TextSize = size
+	c.text.Refresh()
+}
+
+func (c *Countdown) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.text)
+}
+
+func (c *Countdown) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		background, foregroundChanged := appState()
+		interval := c.interval()
+		if background {
+			interval = backgroundCountdownInterval
+		} else if !c.shown() {
+			interval = hiddenCountdownInterval
+		}
+		c.update(!background)
+
+		timer.Reset(interval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-foregroundChanged:
+		case <-c.targetChanged:
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+// interval redraws about as often as the shown time changes.
+func (c *Countdown) interval() time.Duration {
+	return min(max(c.precision, minCountdownInterval), time.Second)
+}
+
+func (c *Countdown) shown() bool {
+	return c.Visible() && fyne.CurrentApp().Driver().CanvasForObject(c) != nil
+}
+
+// update checks the thresholds and, if draw is set, redraws the time.
+func (c *Countdown) update(draw bool) {
+	c.mu.Lock()
+	if c.target.IsZero() || c.finished {
+		c.mu.Unlock()
+		return
+	}
+	value := time.Since(c.target)
+	if c.mode == CountDown {
+		value = time.Until(c.target)
+	}
+
+	var callbacks []func()
+	var textColor color.Color = theme.ForegroundColor()
+	for i, threshold := range c.thresholds {
+		reached := value >= threshold.At
+		if c.mode == CountDown {
+			reached = value <= threshold.At
+		}
+		if !reached {
+			continue
+		}
+		if threshold.Color != nil {
+			textColor = threshold.Color
+		}
+		if !c.reached[i] && c.checked && threshold.OnReached != nil {
+			callbacks = append(callbacks, threshold.OnReached)
+		}
+		c.reached[i] = true
+	}
+	c.checked = true
+
+	text := ""
+	if c.mode == CountDown && value <= 0 {
+		c.finished = true
+		text = c.finishedText
+		if c.onFinished != nil {
+			callbacks = append(callbacks, c.onFinished)
+		}
+	}
+	if text == "" {
+		if c.mode == CountDown {
+			value = max(value, 0)
+		}
+		text = c.format(value)
+	}
+	// once finished nothing is drawn anymore, so the last text must show
+	draw = draw || c.finished
+	c.mu.Unlock()
+
+	if draw {
+		c.text.Text = text
+		c.text.Color = textColor
+		c.text.Refresh()
+	}
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// formatTimer writes a duration as hours, minutes and seconds with as many
+// decimals as the precision needs. Above a minute of precision the seconds
+// are left out.
+func formatTimer(d time.Duration, precision time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	if precision <= 0 {
+		precision = time.Second
+	}
+	d = d.Truncate(precision)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	if precision >= time.Minute {
+		return fmt.Sprintf("%s%d:%02d", sign, hours, minutes)
+	}
+	text := fmt.Sprintf("%s%02d:%02d", sign, minutes, seconds)
+	if hours > 0 {
+		text = fmt.Sprintf("%s%02d:%02d:%02d", sign, hours, minutes, seconds)
+	}
+	decimals := 0
+	for unit := time.Second; unit > precision && decimals < 9; unit /= 10 {
+		decimals++
+	}
+	if decimals > 0 {
+		unit := time.Second / pow10(decimals)
+		text += fmt.Sprintf(".%0*d", decimals, int64(d%time.Second/unit))
+	}
+	return text
+}
+
+func pow10(n int) time.Duration {
+	result := time.Duration(1)
+	for range n {
+		result *= 10
+	}
+	return result
+}
+
+// newRunPhaseCountdown counts down the hiding time of the run phase, turning
+// to the warning color and alerting the players a few minutes before its end
+// and to the error color in the last minute.
+func newRunPhaseCountdown(ctx context.Context, env env.Env, runStartTime time.Time) *Countdown {
+	countdown := NewCountdown(ctx, "run phase countdown", CountDown, 10*time.Millisecond)
+	countdown.SetTextSize(48)
+	countdown.SetFinished("RUN PHASE DOWN", nil)
+	countdown.AddThreshold(CountdownThreshold{
+		At:    hidingTimeWarning,
+		Color: theme.Color(theme.ColorNameWarning),
+		OnReached: func() {
+			notify(env, notifications.KindTimer, "Hiding time", "The hiding time ends in "+fmt.Sprint(int(hidingTimeWarning.Minutes()))+" minutes")
+		},
+	})
+	countdown.AddThreshold(CountdownThreshold{At: time.Minute, Color: theme.Color(theme.ColorNameError)})
+	workers.Go(ctx, "run phase end", func(ctx context.Context) {
+		countdown.SetTarget(runStartTime.Add(lobbySettings(ctx, env).RunDuration))
+	})
+	return countdown
+}
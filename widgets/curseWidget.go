@@ -1,8 +1,8 @@
 package widgets
 
 import (
+	"context"
 	"reflect"
-	"time"
 
 	fyne "fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,52 +11,48 @@ import (
 
 	"github.com/rs/zerolog/log"
 
-	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/state"
 	"github.com/jkulzer/fib-server/sharedModels"
 )
 
 type CurseWidget struct {
 	widget.BaseWidget
 	content        *widget.Accordion
-	env            env.Env
+	store          *state.Store
 	parentWindow   fyne.Window
 	previousCurses []sharedModels.Card
 }
 
-func NewCurseWidget(env env.Env, parentWindow fyne.Window) *CurseWidget {
+func NewCurseWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *CurseWidget {
 	w := &CurseWidget{
-		env:            env,
 		parentWindow:   parentWindow,
 		previousCurses: nil,
 	}
 	w.ExtendBaseWidget(w)
 
 	w.content = widget.NewAccordion()
-	go func() {
-		for {
-			timer := time.NewTimer(2 * time.Second)
-			<-timer.C
-
-			err := w.SetContent()
-			if err != nil {
-				break
-			}
-			w.BaseWidget.Refresh()
-		}
-	}()
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state")
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	w.store = store
+	w.store.OnChange(ctx, state.CursesChanged, func() {
+		w.SetContent()
+		w.BaseWidget.Refresh()
+	})
+	w.SetContent()
 
 	return w
 }
 
-func (w *CurseWidget) SetContent() error {
-	curses, err := client.GetCurses(w.env, w.parentWindow)
-	if err != nil {
-		log.Err(err).Msg("failed getting curses")
-		dialog.ShowError(err, w.parentWindow)
-		return err
+func (w *CurseWidget) SetContent() {
+	if w.store == nil {
+		return
 	}
-
+	curses := w.store.Curses()
 	if !reflect.DeepEqual(w.previousCurses, curses) {
 		w.content.Items = nil
 		for _, card := range curses {
@@ -67,11 +63,11 @@ func (w *CurseWidget) SetContent() error {
 		}
 	}
 	w.previousCurses = curses
-	return nil
 }
 
 func (w *CurseWidget) Refresh() {
 	w.SetContent()
+	w.BaseWidget.Refresh()
 }
 
 func (w *CurseWidget) CreateRenderer() fyne.WidgetRenderer {
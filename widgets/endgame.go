@@ -0,0 +1,172 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"errors"
+	"image/color"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/location"
+	"github.com/jkulzer/fib-client/mapWidget"
+	"github.com/jkulzer/fib-client/state"
+	"github.com/jkulzer/fib-client/workers"
+)
+
+const hidingZoneCheckInterval = 10 * time.Second
+
+var hidingZoneColor = color.RGBA{R: 46, G: 204, B: 64, A: 255}
+
+type HiderEndgameWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewHiderEndgameWidget is the screen of the hider while the seekers are in
+// the hiding zone. It lets the hider end the game once they are found.
+func NewHiderEndgameWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *HiderEndgameWidget {
+	w := &HiderEndgameWidget{}
+	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
+	w.content = container.NewStack()
+
+	store, err := state.For(env)
+	if err != nil {
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	err = store.Refresh(ctx)
+	if err != nil {
+		showRequestError(err, parentWindow)
+		return w
+	}
+
+	foundButton := widget.NewButton("I've been found", func() {
+		dialog.ShowConfirm("Found", "Did the seekers find you? This ends the game for everyone.", func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			err := api.ConfirmFound(context.Background())
+			if err != nil {
+				log.Err(err).Msg("failed confirming that the hider was found")
+				showRequestError(err, parentWindow)
+				return
+			}
+			RouterFor(parentWindow).Sync()
+		}, parentWindow)
+	})
+	foundButton.Importance = widget.HighImportance
+
+	setLocationButton := widget.NewButton("Set Location", func() {
+		go func() {
+			locationPoint, err := location.GetLocation(parentWindow)
+			if err != nil {
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			err = api.SaveLocation(context.Background(), locationPoint)
+			if err != nil {
+				showRequestError(err, parentWindow)
+			}
+		}()
+	})
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Endgame", container.NewVBox(
+			widget.NewLabel("The seekers are in your hiding zone.\nOnce they find you, confirm it here."),
+			foundButton,
+		)),
+		container.NewTabItem("Map", mapWidget.NewMap(ctx, env, &parentWindow)),
+		container.NewTabItem("Cards", NewCardsWidget(ctx, env, parentWindow)),
+		container.NewTabItem("History", NewHistoryWidget(ctx, env, parentWindow)),
+		container.NewTabItem("Location", container.NewVBox(setLocationButton)),
+	)
+	tabs.SetTabLocation(container.TabLocationBottom)
+	w.content.Add(tabs)
+
+	return w
+}
+
+func (w *HiderEndgameWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+type SeekerEndgameWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewSeekerEndgameWidget is the screen of the seekers once they reached the
+// hiding zone. The endgame questions come first and the hiding zone is drawn
+// on the map as soon as the server confirms a seeker is inside it.
+func NewSeekerEndgameWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *SeekerEndgameWidget {
+	w := &SeekerEndgameWidget{}
+	w.ExtendBaseWidget(w)
+	w.content = container.NewStack()
+
+	store, err := state.For(env)
+	if err != nil {
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	err = store.Refresh(ctx)
+	if err != nil {
+		showRequestError(err, parentWindow)
+		return w
+	}
+
+	mapWidgetInstance := mapWidget.NewMap(ctx, env, &parentWindow)
+	historyWidgetInstance := NewHistoryWidget(ctx, env, parentWindow)
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Questions", NewQuestionWidget(ctx, env, parentWindow, mapWidgetInstance, historyWidgetInstance, true)),
+		container.NewTabItem("Map", mapWidgetInstance),
+		container.NewTabItem("Curses", NewCurseWidget(ctx, env, parentWindow)),
+		container.NewTabItem("History", historyWidgetInstance),
+		container.NewTabItem("Team", container.NewVScroll(NewTeamWidget(ctx, env, parentWindow))),
+	)
+	tabs.SetTabLocation(container.TabLocationBottom)
+	w.content.Add(tabs)
+	showTeamOnMap(ctx, env, mapWidgetInstance)
+
+	workers.Go(ctx, "hiding zone check", func(ctx context.Context) {
+		showHidingZone(ctx, client.NewAPI(env), mapWidgetInstance, parentWindow)
+	})
+
+	return w
+}
+
+// showHidingZone asks the server for the hiding zone until a seeker is
+// inside it and then draws it on the map.
+func showHidingZone(ctx context.Context, api *client.API, mapWidgetInstance *mapWidget.Map, parentWindow fyne.Window) {
+	ticker := time.NewTicker(hidingZoneCheckInterval)
+	defer ticker.Stop()
+	for {
+		hidingZone, err := api.GetHidingZone(ctx)
+		if err == nil {
+			mapWidgetInstance.SetHighlight(hidingZone, hidingZoneColor)
+			dialog.ShowInformation("Hiding zone", "You are inside the hiding zone. It is now shown on the map.", parentWindow)
+			return
+		}
+		if !errors.Is(err, client.ErrNotInHidingZone) {
+			log.Err(err).Msg("failed getting hiding zone")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *SeekerEndgameWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
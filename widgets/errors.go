@@ -0,0 +1,36 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/mapWidget"
+)
+
+func init() {
+	mapWidget.ShowRequestError = showRequestError
+}
+
+// showRequestError presents a failed request to the user. Actions that were
+// queued because the server couldn't be reached are shown as information,
+// since they aren't lost. Requests rejected because of an expired session
+// aren't shown at all, the user is already asked to log in again.
+func showRequestError(err error, parentWindow fyne.Window) {
+	if errors.Is(err, client.ErrSessionExpired) {
+		log.Info().Msg(fmt.Sprint(err))
+		return
+	}
+	if errors.Is(err, client.ErrQueued) {
+		log.Info().Msg(fmt.Sprint(err))
+		dialog.ShowInformation("Offline", err.Error(), parentWindow)
+		return
+	}
+	log.Err(err).Msg(fmt.Sprint(err))
+	dialog.ShowError(err, parentWindow)
+}
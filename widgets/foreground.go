@@ -0,0 +1,51 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	foregroundMu sync.Mutex
+	watchingApp  sync.Once
+	inBackground bool
+	// closed and replaced whenever the app enters or leaves the foreground
+	foregroundChanged = make(chan struct{})
+)
+
+// watchForeground follows whether the app is in the foreground. On desktops
+// the app leaves the foreground when its window loses the focus.
+func watchForeground(app fyne.App) {
+	watchingApp.Do(func() {
+		app.Lifecycle().SetOnEnteredForeground(func() {
+			setBackground(false)
+		})
+		app.Lifecycle().SetOnExitedForeground(func() {
+			setBackground(true)
+		})
+	})
+}
+
+func setBackground(background bool) {
+	foregroundMu.Lock()
+	defer foregroundMu.Unlock()
+	if inBackground == background {
+		return
+	}
+	log.Debug().Msg("app in background: " + fmt.Sprint(background))
+	inBackground = background
+	close(foregroundChanged)
+	foregroundChanged = make(chan struct{})
+}
+
+// appState returns whether the app is in the background and a channel which
+// is closed once that changes.
+func appState() (background bool, changed <-chan struct{}) {
+	foregroundMu.Lock()
+	defer foregroundMu.Unlock()
+	return inBackground, foregroundChanged
+}
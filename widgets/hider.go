@@ -1,56 +0,0 @@
-package widgets
-
-import (
-	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/dialog"
-	"fyne.io/fyne/v2/widget"
-
-	// "bytes"
-	// "encoding/json"
-	"errors"
-	"fmt"
-	// "net/http"
-	//
-	"github.com/rs/zerolog/log"
-
-	"github.com/jkulzer/fib-client/client"
-	"github.com/jkulzer/fib-client/env"
-
-	"github.com/jkulzer/fib-server/sharedModels"
-)
-
-type HiderWidget struct {
-	widget.BaseWidget
-	content *fyne.Container
-}
-
-func NewHiderWidget(env env.Env, parentWindow fyne.Window) *HiderWidget {
-	w := &HiderWidget{}
-	w.ExtendBaseWidget(w)
-	w.content = container.NewVBox()
-	gamePhase := client.GetGamePhase(env, parentWindow)
-
-	log.Info().Msg("game phase of lobby is " + fmt.Sprint(gamePhase))
-	switch gamePhase {
-	case sharedModels.PhaseBeforeStart:
-		w.content = container.NewVBox(NewReadinessWidget(env, parentWindow))
-	case sharedModels.PhaseRun:
-		w.content = container.NewVBox(NewHiderRunPhaseWidget(env, parentWindow))
-	case sharedModels.PhaseLocationNarrowing:
-		w.content = container.NewStack(NewHiderNarrowingPhaseWidget(env, parentWindow))
-	case sharedModels.PhaseEndgame:
-		w.content = container.NewStack(NewHiderNarrowingPhaseWidget(env, parentWindow))
-	case sharedModels.PhaseFinished:
-	default:
-		error := errors.New("invalid game state: " + fmt.Sprint(gamePhase))
-		log.Err(error).Msg(fmt.Sprint(error) + " in NewHiderWidget")
-		dialog.ShowError(error, parentWindow)
-	}
-
-	return w
-}
-
-func (w *HiderWidget) CreateRenderer() fyne.WidgetRenderer {
-	return widget.NewSimpleRenderer(w.content)
-}
@@ -12,42 +12,42 @@ import (
 	//
 	// "github.com/rs/zerolog/log"
 
-	"github.com/paulmach/orb/geojson"
-
 	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/location"
 	"github.com/jkulzer/fib-client/mapWidget"
+	"github.com/jkulzer/fib-client/state"
 	// "github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
 )
 
 type HiderNarrowingPhaseWidget struct {
 	widget.BaseWidget
 	content *fyne.Container
-	fc      *geojson.FeatureCollection
 }
 
-func NewHiderNarrowingPhaseWidget(env env.Env, parentWindow fyne.Window) *HiderNarrowingPhaseWidget {
+func NewHiderNarrowingPhaseWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *HiderNarrowingPhaseWidget {
 	w := &HiderNarrowingPhaseWidget{}
 	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
 
 	w.content = container.NewStack()
 
-	mapData, err := client.GetMapData(env, parentWindow)
+	store, err := state.For(env)
 	if err != nil {
 		dialog.ShowError(err, parentWindow)
 		return w
 	}
-
-	w.fc, err = geojson.UnmarshalFeatureCollection(mapData)
+	err = store.Refresh(ctx)
 	if err != nil {
-		dialog.ShowError(err, parentWindow)
+		showRequestError(err, parentWindow)
 		return w
 	}
 
-	mapWidgetInstance := mapWidget.NewMap(w.fc, env, &parentWindow)
-	historyWidgetInstance := NewHistoryWidget(env, parentWindow)
-	cardsWidgetInstance := NewCardsWidget(env, parentWindow)
+	mapWidgetInstance := mapWidget.NewMap(ctx, env, &parentWindow)
+	historyWidgetInstance := NewHistoryWidget(ctx, env, parentWindow)
+	cardsWidgetInstance := NewCardsWidget(ctx, env, parentWindow)
 
 	setLocationButton := widget.NewButton("Set Location", func() {
 		go func() {
@@ -56,9 +56,9 @@ func NewHiderNarrowingPhaseWidget(env env.Env, parentWindow fyne.Window) *HiderN
 				dialog.ShowError(err, parentWindow)
 				return
 			}
-			err = client.SaveLocation(env, parentWindow, locationPoint)
+			err = api.SaveLocation(context.Background(), locationPoint)
 			if err != nil {
-				dialog.ShowError(err, parentWindow)
+				showRequestError(err, parentWindow)
 			}
 		}()
 	})
@@ -2,24 +2,20 @@ package widgets
 
 import (
 	fyne "fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/theme"
 	// "fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/location"
-
-	"github.com/jkulzer/fib-server/sharedModels"
 )
 
 type HiderRunPhaseWidget struct {
@@ -27,9 +23,10 @@ type HiderRunPhaseWidget struct {
 	content *fyne.Container
 }
 
-func NewHiderRunPhaseWidget(env env.Env, parentWindow fyne.Window) *HiderRunPhaseWidget {
+func NewHiderRunPhaseWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *HiderRunPhaseWidget {
 	w := &HiderRunPhaseWidget{}
 	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
 
 	saveLocationButton := widget.NewButton("Save Hiding Zone", func() {
 		go func() {
@@ -38,10 +35,10 @@ func NewHiderRunPhaseWidget(env env.Env, parentWindow fyne.Window) *HiderRunPhas
 				log.Err(err).Msg(fmt.Sprint(err) + " failed getting location in run phase widget")
 				dialog.ShowError(err, parentWindow)
 			}
-			err = client.ValidateAndSetHidingZone(env, parentWindow, point)
+			err = api.ValidateAndSetHidingZone(context.Background(), point)
 			if err != nil {
 				log.Err(err).Msg(fmt.Sprint(err))
-				dialog.ShowError(err, parentWindow)
+				showRequestError(err, parentWindow)
 				return
 			}
 			dialog.ShowInformation("Location", "Saved hiding zone location", parentWindow)
@@ -54,66 +51,24 @@ func NewHiderRunPhaseWidget(env env.Env, parentWindow fyne.Window) *HiderRunPhas
 		saveLocationButton,
 	)
 
-	runStartTime, err := client.RunStartTime(env, parentWindow)
+	runStartTime, err := api.RunStartTime(ctx)
 	if err != nil {
 		log.Err(err).Msg(fmt.Sprint(err))
-		dialog.ShowError(err, parentWindow)
+		showRequestError(err, parentWindow)
 	}
 
-	// Create text object with large font
-	countdownText := canvas.NewText("Countdown initializing", theme.ForegroundColor())
-	countdownText.Alignment = fyne.TextAlignCenter
-	countdownText.TextSize = 48 // Big font size
-	countdownText.TextStyle = fyne.TextStyle{Bold: true}
+	countdown := newRunPhaseCountdown(ctx, env, runStartTime)
 
 	// Create centered container with padding
 	centered := container.New(
 		layout.NewPaddedLayout(),
 		container.NewCenter(
-			countdownText,
+			countdown,
 		),
 	)
 
 	w.content.Add(centered)
 
-	go func() {
-		endTime := runStartTime.Add(sharedModels.RunDuration)
-		ticker := time.NewTicker(50 * time.Millisecond) // Smooth animation
-		defer ticker.Stop()
-
-		updateText := func(s string) {
-			countdownText.Text = s
-			canvas.Refresh(countdownText) // Force redraw
-		}
-
-		for {
-			select {
-			case <-ticker.C:
-				remaining := time.Until(endTime)
-				if remaining <= 0 {
-					updateText("RUN PHASE DOWN")
-					return
-				}
-
-				// Format with milliseconds
-				remaining = remaining.Truncate(10 * time.Millisecond)
-				hours := int(remaining.Hours())
-				minutes := int(remaining.Minutes()) % 60
-				seconds := int(remaining.Seconds()) % 60
-				millis := remaining.Milliseconds() % 1000 / 10
-
-				var timeStr string
-				if hours > 0 {
-					timeStr = fmt.Sprintf("%02d:%02d:%02d.%02d", hours, minutes, seconds, millis)
-				} else {
-					timeStr = fmt.Sprintf("%02d:%02d.%02d", minutes, seconds, millis)
-				}
-
-				updateText(timeStr)
-			}
-		}
-	}()
-
 	return w
 }
 
@@ -8,55 +8,72 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"context"
+
 	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
-	"github.com/jkulzer/fib-server/sharedModels"
+	"github.com/jkulzer/fib-client/state"
 )
 
 type HistoryWidget struct {
 	widget.BaseWidget
 	content      *widget.Accordion
-	history      sharedModels.History
-	env          env.Env
+	store        *state.Store
 	parentWindow fyne.Window
 }
 
-func NewHistoryWidget(env env.Env, parentWindow fyne.Window) *HistoryWidget {
+func NewHistoryWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *HistoryWidget {
 	w := &HistoryWidget{}
 	w.ExtendBaseWidget(w)
 	w.content = widget.NewAccordion()
-
-	w.env = env
 	w.parentWindow = parentWindow
-	log.Debug().Msg("getting history")
-	history, err := client.GetHistory(w.env, w.parentWindow)
+
+	store, err := state.For(env)
 	if err != nil {
-		log.Err(err).Msg("failed getting history")
-		dialog.ShowError(err, w.parentWindow)
+		log.Err(err).Msg("failed getting lobby state")
+		dialog.ShowError(err, parentWindow)
+		return w
 	}
-	w.history = history
+	w.store = store
+	w.store.OnChange(ctx, state.HistoryChanged, w.setContent)
+	w.setContent()
 	return w
 }
 
-func (w *HistoryWidget) Refresh() {
-	log.Debug().Msg("getting history")
-	history, err := client.GetHistory(w.env, w.parentWindow)
-	if err != nil {
-		log.Err(err).Msg("failed getting history")
-		dialog.ShowError(err, w.parentWindow)
-	}
-	w.history = history
-
+func (w *HistoryWidget) setContent() {
 	w.content.Items = nil
-	for _, item := range w.history {
+	for _, item := range w.store.History() {
 		itemContainer := widget.NewAccordionItem(
-			item.Title, container.NewVBox(widget.NewLabel(item.Description)),
+			historyItemTitle(item), container.NewVBox(widget.NewLabel(item.Description)),
 		)
 		w.content.Append(itemContainer)
 	}
 	w.BaseWidget.Refresh()
 }
 
+// historyItemTitle names the seeker who asked the question, if known.
+func historyItemTitle(item client.HistoryItem) string {
+	if item.AskedBy == "" {
+		return item.Title
+	}
+	return item.Title + " (asked by " + item.AskedBy + ")"
+}
+
+// Refresh fetches the history from the server right away instead of waiting
+// for the next update of the lobby state.
+func (w *HistoryWidget) Refresh() {
+	if w.store == nil {
+		return
+	}
+	log.Debug().Msg("getting history")
+	err := w.store.RefreshHistory(context.Background())
+	if err != nil {
+		log.Err(err).Msg("failed getting history")
+		showRequestError(err, w.parentWindow)
+	}
+	w.BaseWidget.Refresh()
+}
+
 func (w *HistoryWidget) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(container.NewScroll(w.content))
 }
@@ -0,0 +1,141 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/workers"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+// players whose app didn't talk to the server for this long are shown as idle
+const idleAfter = 2 * time.Minute
+
+type HostPanelWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewHostPanelWidget lets the host of the lobby kick idle players and
+// reassign roles before the game starts. It stays empty for everyone else.
+func NewHostPanelWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *HostPanelWidget {
+	w := &HostPanelWidget{}
+	w.ExtendBaseWidget(w)
+	w.content = container.NewVBox()
+
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err != nil {
+		log.Err(err).Msg("failed to get app config for the host panel")
+		return w
+	}
+	api := client.NewAPI(env)
+
+	update := func(details client.LobbyDetails) {
+		w.content.RemoveAll()
+		if details.Host != loginInfo.Username || details.Phase != sharedModels.PhaseBeforeStart {
+			return
+		}
+		players := container.NewVBox()
+		for _, player := range details.Players {
+			if player.Username == loginInfo.Username {
+				continue
+			}
+			players.Add(newHostPlayerRow(api, parentWindow, details.LobbyToken, player))
+		}
+		if len(players.Objects) == 0 {
+			players.Add(widget.NewLabel("Nobody else joined yet"))
+		}
+		w.content.Add(widget.NewCard("Host", "Manage the players before the game starts", players))
+	}
+
+	details, err := api.GetLobbyDetails(ctx, loginInfo.LobbyToken)
+	if err != nil {
+		log.Warn().Msg("couldn't get lobby details for the host panel: " + err.Error())
+	} else {
+		update(details)
+	}
+
+	events, err := api.Subscribe(ctx, client.EventLobby)
+	if err != nil {
+		log.Err(err).Msg("failed subscribing to lobby events")
+		return w
+	}
+	workers.Go(ctx, "host panel listener", func(ctx context.Context) {
+		for event := range events {
+			var details client.LobbyDetails
+			err := event.Decode(&details)
+			if err != nil {
+				log.Err(err).Msg("failed decoding lobby event")
+				continue
+			}
+			update(details)
+		}
+	})
+
+	return w
+}
+
+func (w *HostPanelWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+// newHostPlayerRow shows a player with a role picker and a kick button.
+func newHostPlayerRow(api *client.API, parentWindow fyne.Window, lobbyToken string, player client.Player) fyne.CanvasObject {
+	description := player.Username
+	if !player.LastSeen.IsZero() && time.Since(player.LastSeen) > idleAfter {
+		description += " (idle for " + time.Since(player.LastSeen).Truncate(time.Minute).String() + ")"
+	}
+
+	roleOptions := []string{roleNames[sharedModels.NoRole], roleNames[sharedModels.Hider], roleNames[sharedModels.Seeker]}
+	rolesByName := map[string]sharedModels.UserRole{
+		roleNames[sharedModels.NoRole]: sharedModels.NoRole,
+		roleNames[sharedModels.Hider]:  sharedModels.Hider,
+		roleNames[sharedModels.Seeker]: sharedModels.Seeker,
+	}
+	roleSelect := widget.NewSelect(roleOptions, nil)
+	roleSelect.SetSelected(roleNames[player.Role])
+	roleSelect.OnChanged = func(selected string) {
+		role := rolesByName[selected]
+		if role == player.Role {
+			return
+		}
+		go func() {
+			err := api.AssignRole(context.Background(), lobbyToken, player.Username, role)
+			if err != nil {
+				showRequestError(err, parentWindow)
+				roleSelect.SetSelected(roleNames[player.Role])
+				return
+			}
+			log.Info().Msg("made " + player.Username + " " + selected)
+		}()
+	}
+
+	kickButton := widget.NewButton("Kick", func() {
+		dialog.ShowConfirm("Kick player", "Remove "+player.Username+" from the lobby?", func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			go func() {
+				err := api.KickPlayer(context.Background(), lobbyToken, player.Username)
+				if err != nil {
+					showRequestError(err, parentWindow)
+					return
+				}
+				log.Info().Msg("kicked " + player.Username)
+			}()
+		}, parentWindow)
+	})
+	kickButton.Importance = widget.DangerImportance
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(roleSelect, kickButton), widget.NewLabel(description))
+}
@@ -0,0 +1,177 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/invite"
+	"github.com/jkulzer/fib-client/servers"
+)
+
+const qrCodeSize = 256
+
+var (
+	pendingInviteMu sync.Mutex
+	// lobby of an invite opened before the user logged in on its server
+	pendingInvite string
+)
+
+// newInviteContent shows the QR code of the invite link of the lobby, with
+// buttons to copy the link and the plain code.
+func newInviteContent(env env.Env, parentWindow fyne.Window, lobbyToken string) fyne.CanvasObject {
+	link := invite.Link(env.Url, lobbyToken)
+	content := container.NewVBox()
+
+	png, err := invite.QRCode(link, qrCodeSize)
+	if err != nil {
+		log.Err(err).Msg("failed rendering QR code for lobby " + lobbyToken)
+	} else {
+		qrImage := canvas.NewImageFromResource(fyne.NewStaticResource("invite-"+lobbyToken+".png", png))
+		qrImage.FillMode = canvas.ImageFillContain
+		qrImage.SetMinSize(fyne.NewSize(qrCodeSize, qrCodeSize))
+		content.Add(qrImage)
+	}
+
+	content.Add(widget.NewLabelWithStyle(lobbyToken, fyne.TextAlignCenter, fyne.TextStyle{Bold: true, Monospace: true}))
+	content.Add(container.NewGridWithColumns(2,
+		widget.NewButton("Copy link", func() {
+			parentWindow.Clipboard().SetContent(link)
+		}),
+		widget.NewButton("Copy code", func() {
+			parentWindow.Clipboard().SetContent(lobbyToken)
+		}),
+	))
+	return content
+}
+
+// showInviteDialog shows the QR code of the lobby, so other players can scan
+// it instead of typing the code.
+func showInviteDialog(env env.Env, parentWindow fyne.Window, lobbyToken string) {
+	dialog.ShowCustom("Invite to lobby", "Close", newInviteContent(env, parentWindow, lobbyToken), parentWindow)
+}
+
+// showImportInviteDialog reads an invite from a pasted link or code, or from
+// an image of its QR code. On phones the file picker also offers the camera
+// roll, so a photo of another screen works too.
+func showImportInviteDialog(parentWindow fyne.Window) {
+	linkEntry := widget.NewEntry()
+	linkEntry.SetPlaceHolder("fib://join?... or AG5L3T")
+	linkEntry.Validator = func(text string) error {
+		_, err := invite.Parse(text)
+		return err
+	}
+
+	var importDialog dialog.Dialog
+	pasteButton := widget.NewButton("Paste", func() {
+		linkEntry.SetText(parentWindow.Clipboard().Content())
+	})
+	imageButton := widget.NewButton("Open QR code image", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			defer reader.Close()
+			lobbyInvite, err := invite.Decode(reader)
+			if err != nil {
+				log.Warn().Msg("failed reading invite from " + reader.URI().String() + ": " + fmt.Sprint(err))
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			importDialog.Hide()
+			openInvite(parentWindow, lobbyInvite)
+		}, parentWindow)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg"}))
+		fileDialog.Show()
+	})
+
+	items := []*widget.FormItem{
+		{Text: "Invite", Widget: container.NewBorder(nil, nil, nil, pasteButton, linkEntry), HintText: "Paste the invite link or the lobby code"},
+		widget.NewFormItem("", imageButton),
+	}
+	importDialog = dialog.NewForm("Join with invite", "Join", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		OpenInvite(parentWindow, linkEntry.Text)
+	}, parentWindow)
+	importDialog.Resize(fyne.NewSize(400, 250))
+	importDialog.Show()
+}
+
+// OpenInvite joins the lobby of an invite link or a plain lobby code, like
+// the one passed to the app when it's opened through a link.
+func OpenInvite(parentWindow fyne.Window, text string) {
+	lobbyInvite, err := invite.Parse(text)
+	if err != nil {
+		log.Warn().Msg("ignoring invalid invite " + text)
+		dialog.ShowError(err, parentWindow)
+		return
+	}
+	openInvite(parentWindow, lobbyInvite)
+}
+
+// openInvite joins the lobby of the invite. An invite for another server
+// switches the server after asking. If the user isn't logged in on that
+// server yet, the lobby is joined right after the login.
+func openInvite(parentWindow fyne.Window, lobbyInvite invite.Invite) {
+	r := RouterFor(parentWindow)
+	if lobbyInvite.ServerUrl == "" || lobbyInvite.ServerUrl == r.Env().Url {
+		go joinInvitedLobby(parentWindow, lobbyInvite.LobbyToken)
+		return
+	}
+	message := "The invite is for the server " + lobbyInvite.ServerUrl + ".\nSwitch to it and join lobby " + lobbyInvite.LobbyToken + "?"
+	dialog.ShowConfirm("Switch server", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		go func() {
+			profile, err := servers.Use(r.Env().DB, "", lobbyInvite.ServerUrl)
+			if err != nil {
+				log.Err(err).Msg("failed saving server of invite")
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			r.SetServer(profile.Url)
+			joinInvitedLobby(parentWindow, lobbyInvite.LobbyToken)
+		}()
+	}, parentWindow)
+}
+
+func joinInvitedLobby(parentWindow fyne.Window, lobbyToken string) {
+	env := RouterFor(parentWindow).Env()
+	_, err := helpers.GetAppConfig(env)
+	if err != nil {
+		log.Info().Msg("joining lobby " + lobbyToken + " after the login on " + env.Url)
+		pendingInviteMu.Lock()
+		pendingInvite = lobbyToken
+		pendingInviteMu.Unlock()
+		dialog.ShowInformation("Invite", "Log in on "+env.Url+" to join lobby "+lobbyToken+".", parentWindow)
+		return
+	}
+	joinLobby(lobbyToken, parentWindow, env)
+}
+
+// takePendingInvite returns the lobby of an invite waiting for the login and
+// forgets it.
+func takePendingInvite() string {
+	pendingInviteMu.Lock()
+	defer pendingInviteMu.Unlock()
+	lobbyToken := pendingInvite
+	pendingInvite = ""
+	return lobbyToken
+}
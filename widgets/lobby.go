@@ -7,17 +7,17 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/helpers"
-	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/lobbies"
+	"github.com/jkulzer/fib-client/state"
 	"github.com/jkulzer/fib-server/sharedModels"
 )
 
@@ -31,24 +31,14 @@ func NewLobbyWidget(env env.Env, parentWindow fyne.Window) *LobbyWidget {
 	w.ExtendBaseWidget(w)
 
 	logoutButton := widget.NewButton("Logout", func() {
-		dialog.ShowConfirm("Logout", "Are you sure you want to log out?", func(confirmed bool) {
-			if confirmed {
-				result := env.DB.Delete(&models.LoginInfo{}, 1)
-				if result.Error != nil {
-					log.Err(result.Error).Msg(fmt.Sprint(result.Error))
-					dialog.ShowError(result.Error, parentWindow)
-				}
-				loginRegisterTabs := GetLoginRegisterTabs(env, parentWindow)
-				parentWindow.SetContent(loginRegisterTabs)
-			}
-		}, parentWindow)
+		confirmLogout(env, parentWindow)
 	})
 
-	top := container.NewHBox(logoutButton)
+	top := container.NewHBox(logoutButton, newProfileButton(parentWindow), newAccountsButton(parentWindow))
 
 	middle := NewLobbySelectionWidget(env, parentWindow)
 
-	w.content = container.NewBorder(top, nil, nil, nil, middle)
+	w.content = container.NewBorder(top, nil, nil, nil, container.NewVScroll(middle))
 
 	return w
 }
@@ -69,9 +59,6 @@ func NewLobbySelectionWidget(env env.Env, parentWindow fyne.Window) *LobbySelect
 	lobbyCodeEntry.SetPlaceHolder("AG5L3T")
 	lobbyCodeEntry.Validator = validation.NewRegexp(sharedModels.LobbyCodeRegex, "Lobby code must be 6 characters")
 
-	var appConfiguration models.LoginInfo
-	env.DB.First(&appConfiguration)
-
 	lobbyEntryForm := &widget.Form{
 		Items: []*widget.FormItem{
 			{Text: "Lobby Code", Widget: lobbyCodeEntry},
@@ -85,73 +72,16 @@ func NewLobbySelectionWidget(env env.Env, parentWindow fyne.Window) *LobbySelect
 	}
 
 	lobbyCreationButton := widget.NewButton("Create Lobby", func() {
-		req, err := http.NewRequest("POST", env.Url+"/lobby/create", nil)
-		if err != nil {
-			dialog.ShowError(err, parentWindow)
-		}
-		// error handing already handled since it shows a popup
-		loginInfo, err := helpers.GetAppConfig(env, parentWindow)
-		if err == nil {
-			req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-			res, err := http.DefaultClient.Do(req)
-			if err != nil {
-				log.Warn().Msg("couldn't make request" + fmt.Sprint(err))
-				dialog.ShowError(err, parentWindow)
-				return
-			}
-			switch res.StatusCode {
-			case http.StatusCreated:
-				var responseStruct sharedModels.LobbyCreationResponse
-				responseBytes, err := helpers.ReadHttpResponse(res.Body)
-				if err != nil {
-					log.Err(err).Msg("failed to read http response for lobby creation")
-					dialog.ShowError(err, parentWindow)
-				}
-				err = json.Unmarshal(responseBytes, &responseStruct)
-				if err != nil {
-					message := "Failed to unmarshal http response"
-					log.Err(err).Msg(message)
-					dialog.ShowError(err, parentWindow)
-				}
-				appConfig, err := helpers.GetAppConfig(env, parentWindow)
-				if err != nil {
-					log.Err(err)
-					dialog.ShowError(err, parentWindow)
-				}
-				appConfig.LobbyToken = responseStruct.LobbyToken
-				// tries to create the user in the db
-				result := env.DB.Save(&appConfig)
-				if result.Error != nil {
-					log.Err(err).Msg("failed to save configuration in database")
-					dialog.ShowError(err, parentWindow)
-				} else {
-					log.Info().Msg("created lobby " + responseStruct.LobbyToken)
-					creationDialog := dialog.NewCustom("Lobby Creation", "Close", container.NewVBox(
-						widget.NewLabel("Created lobby with token \""+responseStruct.LobbyToken+"\""),
-						widget.NewButton("Copy to clipboard", func() {
-							fyne.Clipboard.SetContent(parentWindow.Clipboard(), responseStruct.LobbyToken)
-						}),
-					),
-						parentWindow,
-					)
-					creationDialog.Show()
-
-					// dialog.ShowInformation("Lobby Creation", "Created lobby with token \""+responseStruct.LobbyToken+"\"", parentWindow)
-					joinLobby(responseStruct.LobbyToken, parentWindow, env)
-				}
+		showLobbySettingsDialog(parentWindow, func(settings client.LobbySettings) {
+			createLobby(env, parentWindow, settings)
+		})
+	})
 
-			case http.StatusForbidden:
-				message := "Not logged in. Log out and back in."
-				log.Info().Msg(message)
-				error := errors.New(message)
-				dialog.ShowError(error, parentWindow)
-			default:
-				dialog.ShowError(errors.New(fmt.Sprint(res.StatusCode)), parentWindow)
-			}
-		}
+	importInviteButton := widget.NewButton("Join with invite link or QR code", func() {
+		showImportInviteDialog(parentWindow)
 	})
 
-	lobbyJoin := container.NewVBox(lobbyEntryForm)
+	lobbyJoin := container.NewVBox(lobbyEntryForm, importInviteButton)
 	lobbyCreate := container.NewVBox(lobbyCreationButton)
 
 	w.content = container.NewVBox(
@@ -159,8 +89,16 @@ func NewLobbySelectionWidget(env env.Env, parentWindow fyne.Window) *LobbySelect
 		lobbyJoin,
 		widget.NewLabel("Create a lobby"),
 		lobbyCreate,
+		widget.NewLabel("My lobbies"),
+		NewMyLobbiesWidget(env, parentWindow),
 	)
 
+	// an invite was opened before logging in
+	if lobbyToken := takePendingInvite(); lobbyToken != "" {
+		lobbyCodeEntry.SetText(lobbyToken)
+		go joinLobby(lobbyToken, parentWindow, env)
+	}
+
 	return w
 }
 
@@ -168,97 +106,120 @@ func (w *LobbySelectionWidget) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(w.content)
 }
 
-func joinLobby(lobbyCode string, parentWindow fyne.Window, env env.Env) sharedModels.UserRole {
-	joinRequest := sharedModels.LobbyJoinRequest{
-		LobbyToken: lobbyCode,
+// createLobby creates a lobby with the settings and joins it.
+func createLobby(env env.Env, parentWindow fyne.Window, settings client.LobbySettings) {
+	responseStruct, err := client.NewAPI(env).CreateLobby(context.Background(), settings)
+	if err != nil {
+		log.Warn().Msg("couldn't create lobby: " + fmt.Sprint(err))
+		showRequestError(err, parentWindow)
+		return
 	}
-	joinJson, err := json.Marshal(joinRequest)
+	appConfig, err := helpers.GetAppConfig(env)
 	if err != nil {
-		log.Warn().Msg("failed to marshall login info json")
+		log.Err(err)
 		dialog.ShowError(err, parentWindow)
-		return sharedModels.NoRole
+		return
+	}
+	appConfig.LobbyToken = responseStruct.LobbyToken
+	// tries to create the user in the db
+	result := env.DB.Save(&appConfig)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("failed to save configuration in database")
+		dialog.ShowError(result.Error, parentWindow)
+	} else {
+		log.Info().Msg("created lobby " + responseStruct.LobbyToken)
+		creationDialog := dialog.NewCustom("Lobby Creation", "Close", container.NewVBox(
+			widget.NewLabel("Created lobby with token \""+responseStruct.LobbyToken+"\""),
+			newInviteContent(env, parentWindow, responseStruct.LobbyToken),
+		),
+			parentWindow,
+		)
+		creationDialog.Show()
+
+		// dialog.ShowInformation("Lobby Creation", "Created lobby with token \""+responseStruct.LobbyToken+"\"", parentWindow)
+		joinLobby(responseStruct.LobbyToken, parentWindow, env)
 	}
+}
 
-	bodyReader := bytes.NewReader(joinJson)
-	req, err := http.NewRequest("POST", env.Url+"/lobby/join", bodyReader)
+func joinLobby(lobbyCode string, parentWindow fyne.Window, env env.Env) sharedModels.UserRole {
+	joinResponse, err := client.NewAPI(env).JoinLobby(context.Background(), lobbyCode)
 	if err != nil {
-		dialog.ShowError(err, parentWindow)
+		log.Info().Msg("couldn't join lobby: " + fmt.Sprint(err))
+		showRequestError(err, parentWindow)
 		return sharedModels.NoRole
 	}
-	// error handing already handled since it shows a popup
-	loginInfo, err := helpers.GetAppConfig(env, parentWindow)
+	appConfig, err := helpers.GetAppConfig(env)
 	if err != nil {
-		log.Warn().Msg("couldn't get app config" + fmt.Sprint(err))
+		log.Err(err)
 		dialog.ShowError(err, parentWindow)
 		return sharedModels.NoRole
 	}
-	req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-	res, err := http.DefaultClient.Do(req)
+	state.CloseAll()
+	appConfig.LobbyToken = lobbyCode
+	appConfig.Role = joinResponse.CurrentRole
+	// tries to create the user in the db
+	result := env.DB.Save(&appConfig)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("failed to save configuration in database")
+		dialog.ShowError(result.Error, parentWindow)
+		return sharedModels.NoRole
+	}
+	err = lobbies.Remember(env.DB, appConfig.ID, lobbyCode)
+	if err != nil {
+		log.Err(err).Msg("failed remembering lobby " + lobbyCode)
+	}
+	log.Info().Msg("joined lobby " + lobbyCode)
+	log.Debug().Msg("role is " + fmt.Sprint(joinResponse.CurrentRole))
+	RouterFor(parentWindow).Sync()
+	return joinResponse.CurrentRole
+}
+
+// switchLobby saves the lobby and role the user is in now and shows the
+// matching screen. An empty lobbyToken leaves the current lobby.
+func switchLobby(lobbyToken string, role sharedModels.UserRole, parentWindow fyne.Window, env env.Env) error {
+	appConfig, err := helpers.GetAppConfig(env)
 	if err != nil {
-		log.Warn().Msg("couldn't make request" + fmt.Sprint(err))
+		log.Err(err).Msg("failed to get app config while switching lobby")
 		dialog.ShowError(err, parentWindow)
-		return sharedModels.NoRole
+		return err
 	}
-	switch res.StatusCode {
-	case http.StatusOK:
-		httpBody, err := helpers.ReadHttpResponse(res.Body)
-		if err != nil {
-			log.Err(err).Msg("failed to read http response from body")
-			dialog.ShowError(err, parentWindow)
-			return sharedModels.NoRole
-		}
-		var joinResponse sharedModels.JoinResponse
-		err = json.Unmarshal(httpBody, &joinResponse)
-		if err != nil {
-			log.Err(err).Msg("failed to unmarshal join response")
-			dialog.ShowError(err, parentWindow)
-			return sharedModels.NoRole
-		}
-		appConfig, err := helpers.GetAppConfig(env, parentWindow)
+	state.CloseAll()
+	appConfig.LobbyToken = lobbyToken
+	appConfig.Role = role
+	result := env.DB.Save(&appConfig)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("failed to save configuration in database")
+		dialog.ShowError(result.Error, parentWindow)
+		return result.Error
+	}
+	if lobbyToken != "" {
+		err := lobbies.Remember(env.DB, appConfig.ID, lobbyToken)
 		if err != nil {
-			log.Err(err)
-			dialog.ShowError(err, parentWindow)
-			return sharedModels.NoRole
-		}
-		appConfig.LobbyToken = lobbyCode
-		appConfig.Role = joinResponse.CurrentRole
-		// tries to create the user in the db
-		result := env.DB.Save(&appConfig)
-		if result.Error != nil {
-			log.Err(err).Msg("failed to save configuration in database")
-			dialog.ShowError(err, parentWindow)
-			return sharedModels.NoRole
-		}
-		log.Info().Msg("joined lobby " + lobbyCode)
-		log.Debug().Msg("role is " + fmt.Sprint(joinResponse.CurrentRole))
-		switch joinResponse.CurrentRole {
-		case sharedModels.NoRole:
-			log.Debug().Msg("creating new game widget")
-			parentWindow.SetContent(NewGameWidget(env, parentWindow))
-		case sharedModels.Hider:
-			log.Debug().Msg("creating new hider widget")
-			parentWindow.SetContent(NewHiderWidget(env, parentWindow))
-		case sharedModels.Seeker:
-			log.Debug().Msg("creating new seeker widget")
-			parentWindow.SetContent(NewSeekerWidget(env, parentWindow))
-		default:
-			log.Debug().Msg("unknown role with index " + fmt.Sprint(joinResponse.CurrentRole) + " detected")
+			log.Err(err).Msg("failed remembering lobby " + lobbyToken)
 		}
-	case http.StatusForbidden:
-		message := "Not logged in. Log out and back in."
-		log.Info().Msg(message)
-		error := errors.New(message)
-		dialog.ShowError(error, parentWindow)
-		return sharedModels.NoRole
-	case http.StatusNotFound:
-		message := "Lobby doesn't exist"
-		log.Info().Msg(message)
-		error := errors.New(message)
-		dialog.ShowError(error, parentWindow)
-		return sharedModels.NoRole
-	default:
-		dialog.ShowError(errors.New(fmt.Sprint(res.StatusCode)), parentWindow)
-		return sharedModels.NoRole
 	}
-	return sharedModels.NoRole
+	RouterFor(parentWindow).Sync()
+	return nil
+}
+
+// leaveLobby tells the server the user left the lobby, so their role is free
+// again, and forgets the lobby. Without a connection the server is told once
+// it can be reached again.
+func leaveLobby(env env.Env, parentWindow fyne.Window, lobbyToken string) {
+	err := client.NewAPI(env).LeaveLobby(context.Background(), lobbyToken)
+	// a lobby that is gone or that the user was removed from is left anyway
+	if err != nil && !errors.Is(err, client.ErrQueued) && !errors.Is(err, client.ErrNotInLobby) && !errors.Is(err, client.ErrLobbyNotFound) {
+		log.Err(err).Msg("failed leaving lobby " + lobbyToken)
+		showRequestError(err, parentWindow)
+		return
+	}
+	appConfig, err := helpers.GetAppConfig(env)
+	if err == nil {
+		err = lobbies.Forget(env.DB, appConfig.ID, lobbyToken)
+	}
+	if err != nil {
+		log.Err(err).Msg("failed forgetting lobby " + lobbyToken)
+	}
+	log.Info().Msg("left lobby " + lobbyToken)
+	switchLobby("", sharedModels.NoRole, parentWindow, env)
 }
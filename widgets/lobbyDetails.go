@@ -0,0 +1,168 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/lobbies"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+var roleNames = map[sharedModels.UserRole]string{
+	sharedModels.NoRole: "No role yet",
+	sharedModels.Hider:  "Hider",
+	sharedModels.Seeker: "Seeker",
+}
+
+var phaseNames = map[sharedModels.GamePhase]string{
+	sharedModels.PhaseBeforeStart:       "Waiting for players",
+	sharedModels.PhaseRun:               "Hider is running",
+	sharedModels.PhaseLocationNarrowing: "Seekers are asking questions",
+	sharedModels.PhaseEndgame:           "Endgame",
+	sharedModels.PhaseFinished:          "Finished",
+}
+
+// showLobbyDetails shows who is in the lobby, their roles and whether they
+// are ready.
+func showLobbyDetails(env env.Env, parentWindow fyne.Window, lobbyToken string) {
+	go func() {
+		api := client.NewAPI(env)
+		details, err := api.GetLobbyDetails(context.Background(), lobbyToken)
+		if err != nil {
+			showRequestError(err, parentWindow)
+			return
+		}
+		settings, err := api.GetLobbySettings(context.Background(), lobbyToken)
+		if err != nil {
+			showRequestError(err, parentWindow)
+			return
+		}
+
+		players := container.NewVBox()
+		for _, player := range details.Players {
+			readiness := "not ready"
+			if player.Ready {
+				readiness = "ready"
+			}
+			name := player.Username
+			if name == details.Host {
+				name += " (host)"
+			}
+			players.Add(widget.NewLabel(name + ": " + roleNames[player.Role] + ", " + readiness))
+		}
+		if len(details.Players) == 0 {
+			players.Add(widget.NewLabel("Nobody joined yet"))
+		}
+
+		content := container.NewVBox(
+			widget.NewLabel("Created "+details.CreatedAt.Local().Format(time.DateTime)),
+			widget.NewLabel("Phase: "+phaseNames[details.Phase]),
+			widget.NewLabelWithStyle("Players", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			players,
+			widget.NewLabelWithStyle("Rules", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabel(settingsSummary(settings)),
+		)
+		dialog.ShowCustom("Lobby "+details.LobbyToken, "Close", content, parentWindow)
+	}()
+}
+
+// myLobby is a lobby the user is in, known from the server, this device or
+// both.
+type myLobby struct {
+	lobbyToken string
+	joinedAt   time.Time
+	// only known if the server listed the lobby
+	phase sharedModels.GamePhase
+	role  sharedModels.UserRole
+}
+
+type MyLobbiesWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewMyLobbiesWidget lists the lobbies the user joined, so they can look
+// into them or rejoin one. The list of the server covers other devices, the
+// local list is used when the server can't be reached.
+func NewMyLobbiesWidget(env env.Env, parentWindow fyne.Window) *MyLobbiesWidget {
+	w := &MyLobbiesWidget{}
+	w.ExtendBaseWidget(w)
+	w.content = container.NewVBox()
+
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err != nil {
+		log.Err(err).Msg("failed to get app config for my lobbies")
+		return w
+	}
+
+	byToken := make(map[string]*myLobby)
+	known, err := lobbies.List(env.DB, loginInfo.ID)
+	if err != nil {
+		log.Err(err).Msg("failed listing known lobbies")
+	}
+	for _, k := range known {
+		byToken[k.LobbyToken] = &myLobby{lobbyToken: k.LobbyToken, joinedAt: k.JoinedAt}
+	}
+	summaries, err := client.NewAPI(env).GetMyLobbies(context.Background())
+	if err != nil {
+		log.Warn().Msg("couldn't get lobbies from the server, only showing the ones of this device")
+	}
+	for _, summary := range summaries {
+		lobby, ok := byToken[summary.LobbyToken]
+		if !ok {
+			lobby = &myLobby{lobbyToken: summary.LobbyToken, joinedAt: summary.CreatedAt}
+			byToken[summary.LobbyToken] = lobby
+			err := lobbies.Remember(env.DB, loginInfo.ID, summary.LobbyToken)
+			if err != nil {
+				log.Err(err).Msg("failed remembering lobby " + summary.LobbyToken)
+			}
+		}
+		lobby.phase = summary.Phase
+		lobby.role = summary.Role
+	}
+
+	myLobbies := make([]*myLobby, 0, len(byToken))
+	for _, lobby := range byToken {
+		myLobbies = append(myLobbies, lobby)
+	}
+	sort.Slice(myLobbies, func(i, j int) bool {
+		return myLobbies[i].joinedAt.After(myLobbies[j].joinedAt)
+	})
+
+	if len(myLobbies) == 0 {
+		w.content.Add(widget.NewLabel("You didn't join any lobby yet"))
+	}
+	for _, lobby := range myLobbies {
+		description := lobby.lobbyToken + ", joined " + lobby.joinedAt.Local().Format(time.DateTime)
+		if lobby.phase != sharedModels.PhaseInvalid {
+			description += "\n" + phaseNames[lobby.phase] + ", " + roleNames[lobby.role]
+		}
+		detailsButton := widget.NewButton("Details", func() {
+			showLobbyDetails(env, parentWindow, lobby.lobbyToken)
+		})
+		rejoinButton := widget.NewButton("Rejoin", func() {
+			go joinLobby(lobby.lobbyToken, parentWindow, env)
+		})
+		if lobby.phase == sharedModels.PhaseFinished {
+			rejoinButton.Disable()
+		}
+		w.content.Add(container.NewBorder(nil, nil, nil, container.NewHBox(detailsButton, rejoinButton), widget.NewLabel(description)))
+	}
+
+	return w
+}
+
+func (w *MyLobbiesWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
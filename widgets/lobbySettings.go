@@ -0,0 +1,182 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/state"
+)
+
+var categoryNames = map[client.QuestionCategory]string{
+	client.CategoryMatching:    "Yes/no questions",
+	client.CategoryRelative:    "Relative questions",
+	client.CategoryThermometer: "Thermometer",
+	client.CategoryRadar:       "Radar",
+}
+
+var playAreaNames = map[client.PlayArea]string{
+	client.PlayAreaBerlin: "Berlin",
+	client.PlayAreaRing:   "Inside the S-Bahn ring",
+}
+
+// showLobbySettingsDialog asks for the rules of a new lobby, prefilled with
+// the default ones, and passes them to create.
+func showLobbySettingsDialog(parentWindow fyne.Window, create func(client.LobbySettings)) {
+	defaults := client.DefaultLobbySettings()
+
+	runDurationEntry := widget.NewEntry()
+	runDurationEntry.SetText(fmt.Sprint(defaults.RunDuration.Minutes()))
+	runDurationEntry.Validator = func(text string) error {
+		_, err := strconv.ParseFloat(text, 64)
+		return err
+	}
+
+	handSizeEntry := widget.NewEntry()
+	handSizeEntry.SetText(fmt.Sprint(defaults.MaxHandSize))
+	handSizeEntry.Validator = func(text string) error {
+		_, err := strconv.Atoi(text)
+		return err
+	}
+
+	seekersEntry := widget.NewEntry()
+	seekersEntry.SetText(fmt.Sprint(defaults.MaxSeekers))
+	seekersEntry.Validator = func(text string) error {
+		_, err := strconv.Atoi(text)
+		return err
+	}
+
+	var categoryOptions []string
+	categoriesByName := make(map[string]client.QuestionCategory)
+	for _, category := range client.QuestionCategories {
+		categoryOptions = append(categoryOptions, categoryNames[category])
+		categoriesByName[categoryNames[category]] = category
+	}
+	categoriesCheck := widget.NewCheckGroup(categoryOptions, nil)
+	categoriesCheck.SetSelected(categoryOptions)
+
+	var playAreaOptions []string
+	playAreasByName := make(map[string]client.PlayArea)
+	for _, playArea := range client.PlayAreas {
+		playAreaOptions = append(playAreaOptions, playAreaNames[playArea])
+		playAreasByName[playAreaNames[playArea]] = playArea
+	}
+	playAreaSelect := widget.NewSelect(playAreaOptions, nil)
+	playAreaSelect.SetSelected(playAreaNames[defaults.PlayArea])
+
+	radiiEntry := widget.NewEntry()
+	radiiEntry.SetText(formatRadii(defaults.RadarRadii))
+	radiiEntry.Validator = func(text string) error {
+		_, err := parseRadii(text)
+		return err
+	}
+
+	items := []*widget.FormItem{
+		{Text: "Run phase (minutes)", Widget: runDurationEntry},
+		{Text: "Max hand size", Widget: handSizeEntry},
+		{Text: "Seekers", Widget: seekersEntry, HintText: "How many seekers hunt the hider together"},
+		{Text: "Questions", Widget: categoriesCheck},
+		{Text: "Play area", Widget: playAreaSelect},
+		{Text: "Radar radii (m)", Widget: radiiEntry, HintText: "Comma separated, e.g. 500, 1000"},
+	}
+	settingsDialog := dialog.NewForm("Lobby settings", "Create", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		minutes, _ := strconv.ParseFloat(runDurationEntry.Text, 64)
+		handSize, _ := strconv.Atoi(handSizeEntry.Text)
+		seekers, _ := strconv.Atoi(seekersEntry.Text)
+		radii, _ := parseRadii(radiiEntry.Text)
+		settings := client.LobbySettings{
+			RunDuration: time.Duration(minutes * float64(time.Minute)),
+			MaxHandSize: handSize,
+			PlayArea:    playAreasByName[playAreaSelect.Selected],
+			RadarRadii:  radii,
+			MaxSeekers:  seekers,
+		}
+		for _, name := range categoriesCheck.Selected {
+			settings.QuestionCategories = append(settings.QuestionCategories, categoriesByName[name])
+		}
+		err := settings.Validate()
+		if err != nil {
+			dialog.ShowError(err, parentWindow)
+			return
+		}
+		go create(settings)
+	}, parentWindow)
+	settingsDialog.Resize(fyne.NewSize(400, 550))
+	settingsDialog.Show()
+}
+
+func parseRadii(text string) ([]float64, error) {
+	var radii []float64
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		radius, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		radii = append(radii, radius)
+	}
+	return radii, nil
+}
+
+func formatRadii(radii []float64) string {
+	fields := make([]string, 0, len(radii))
+	for _, radius := range radii {
+		fields = append(fields, fmt.Sprint(radius))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// radiusName formats a radar radius the way the buttons show it, e.g. 500m
+// or 2.5km.
+func radiusName(radius float64) string {
+	if radius >= 1000 {
+		return fmt.Sprint(radius/1000) + "km"
+	}
+	return fmt.Sprint(radius) + "m"
+}
+
+// settingsSummary describes the rules of a lobby for the players.
+func settingsSummary(settings client.LobbySettings) string {
+	var categories []string
+	for _, category := range settings.QuestionCategories {
+		categories = append(categories, categoryNames[category])
+	}
+	return "Run phase: " + fmt.Sprint(settings.RunDuration) +
+		"\nMax hand size: " + fmt.Sprint(settings.MaxHandSize) +
+		"\nSeekers: up to " + fmt.Sprint(settings.SeekerSlots()) +
+		"\nQuestions: " + strings.Join(categories, ", ") +
+		"\nPlay area: " + playAreaNames[settings.PlayArea] +
+		"\nRadar radii: " + formatRadii(settings.RadarRadii) + " m"
+}
+
+// lobbySettings returns the rules of the lobby the user is in. If they can't
+// be fetched the default rules are used, so the game stays playable.
+func lobbySettings(ctx context.Context, env env.Env) client.LobbySettings {
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state for settings")
+		return client.DefaultLobbySettings()
+	}
+	settings, err := store.Settings(ctx)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby settings, using the default ones")
+		return client.DefaultLobbySettings()
+	}
+	return settings
+}
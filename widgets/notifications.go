@@ -0,0 +1,337 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/notifications"
+	"github.com/jkulzer/fib-client/state"
+	"github.com/jkulzer/fib-client/workers"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+// hidingTimeWarning is how long before the end of the hiding time the players
+// are alerted.
+const hidingTimeWarning = 5 * time.Minute
+
+// ownChangeWindow is how long after the user changed the lobby on this device
+// a change of the same kind is taken to be theirs and not alerted about.
+const ownChangeWindow = time.Minute
+
+var notificationKindNames = map[notifications.Kind]string{
+	notifications.KindPhase:       "New game phase",
+	notifications.KindTimer:       "Hiding time ending soon",
+	notifications.KindQuestion:    "New questions",
+	notifications.KindThermometer: "Thermometer running",
+	notifications.KindCurse:       "Curses cast on the seekers",
+	notifications.KindHand:        "New cards in the hider's hand",
+}
+
+var (
+	notificationsMu sync.Mutex
+	watchingLobbies sync.Once
+	// closed and replaced whenever a notification is added or read
+	notificationsChanged = make(chan struct{})
+	// when the user last made a change of the kind on this device
+	ownChanges = make(map[notifications.Kind]time.Time)
+)
+
+// watchLobbies alerts the user about the events of every lobby state from
+// now on.
+func watchLobbies() {
+	watchingLobbies.Do(func() {
+		state.OnCreated(watchNotifications)
+	})
+}
+
+// notify adds a notification to the notification center, unless the user
+// turned off its kind. While the app is in the background it is also sent to
+// the system, in the foreground the screens show the change themselves.
+func notify(env env.Env, kind notifications.Kind, title, content string) {
+	if !notifications.Enabled(env.DB, kind) {
+		return
+	}
+	notification := models.Notification{
+		Kind:      string(kind),
+		Title:     title,
+		Content:   content,
+		ServerUrl: env.Url,
+	}
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err == nil {
+		notification.LobbyToken = loginInfo.LobbyToken
+	}
+	err = notifications.Record(env.DB, notification)
+	if err != nil {
+		log.Err(err).Msg("failed saving notification")
+	}
+	notificationsUpdated()
+
+	background, _ := appState()
+	if background {
+		fyne.CurrentApp().SendNotification(fyne.NewNotification(title, content))
+	}
+	log.Info().Msg("notified about " + string(kind) + ": " + title)
+}
+
+// madeOwnChange keeps the user from being alerted about the change of the
+// kind they just made, e.g. the thermometer they started or the cards they
+// picked.
+func madeOwnChange(kind notifications.Kind) {
+	notificationsMu.Lock()
+	defer notificationsMu.Unlock()
+	ownChanges[kind] = time.Now()
+}
+
+func madeOwnChangeRecently(kind notifications.Kind) bool {
+	notificationsMu.Lock()
+	defer notificationsMu.Unlock()
+	return time.Since(ownChanges[kind]) < ownChangeWindow
+}
+
+func notificationsUpdated() {
+	notificationsMu.Lock()
+	defer notificationsMu.Unlock()
+	close(notificationsChanged)
+	notificationsChanged = make(chan struct{})
+}
+
+// notificationsState returns a channel which is closed once a notification
+// is added or read.
+func notificationsState() <-chan struct{} {
+	notificationsMu.Lock()
+	defer notificationsMu.Unlock()
+	return notificationsChanged
+}
+
+// watchNotifications alerts the user about new questions, thermometers, curses
+// and cards in the lobby of the store. What the store holds when it first
+// hears from the server is already known to the user; only questions asked
+// after the store was created are new then. Questions, thermometers and cards
+// of the user themselves are never new to them.
+func watchNotifications(ctx context.Context, store *state.Store) {
+	env := store.Env()
+	created := time.Now()
+	username := ""
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err == nil {
+		username = loginInfo.Username
+	}
+
+	var mu sync.Mutex
+	// -1 until the history was received
+	seenQuestions := -1
+	store.OnChange(ctx, state.HistoryChanged, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		history := store.History()
+		title := "New question"
+		if store.Role() == sharedModels.Hider {
+			title = "New question to answer"
+		}
+		for i, item := range history {
+			isNew := i >= seenQuestions
+			if seenQuestions < 0 {
+				isNew = item.AskedAt.After(created)
+			}
+			if isNew && (item.AskedBy == "" || item.AskedBy != username) {
+				notify(env, notifications.KindQuestion, title, historyItemTitle(item))
+			}
+		}
+		seenQuestions = len(history)
+	})
+
+	// starting a thermometer moves the thermometer cooldown of the team, zero
+	// until the team was received
+	var seenThermometer time.Time
+	seenTeam := false
+	store.OnChange(ctx, state.TeamChanged, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		until := store.Team().Cooldowns[client.CategoryThermometer]
+		isNew := seenTeam && until.After(seenThermometer)
+		seenTeam = true
+		seenThermometer = until
+		if isNew && !madeOwnChangeRecently(notifications.KindThermometer) {
+			notify(env, notifications.KindThermometer, "Thermometer running", "A seeker of your team started a thermometer")
+		}
+	})
+
+	var seenCurses map[string]int
+	store.OnChange(ctx, state.CursesChanged, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		var curses []sharedModels.Card
+		curses, seenCurses = newCards(seenCurses, store.Curses())
+		for _, curse := range curses {
+			notify(env, notifications.KindCurse, "Curse cast on you", curse.Title)
+		}
+	})
+
+	var seenHand map[string]int
+	store.OnChange(ctx, state.HandChanged, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		var cards []sharedModels.Card
+		cards, seenHand = newCards(seenHand, store.Hand().List)
+		if madeOwnChangeRecently(notifications.KindHand) {
+			return
+		}
+		for _, card := range cards {
+			notify(env, notifications.KindHand, "New card in your hand", card.Title)
+		}
+	})
+}
+
+// newCards returns the cards which weren't seen before, by title, and the
+// cards seen now. Nothing is new while seen is nil.
+func newCards(seen map[string]int, cards []sharedModels.Card) ([]sharedModels.Card, map[string]int) {
+	counts := make(map[string]int)
+	var added []sharedModels.Card
+	for _, card := range cards {
+		counts[card.Title]++
+		if seen != nil && counts[card.Title] > seen[card.Title] {
+			added = append(added, card)
+		}
+	}
+	return added, counts
+}
+
+// newNotificationsButton opens the notification center and shows how many
+// notifications the user didn't see yet.
+func newNotificationsButton(ctx context.Context, env env.Env, parentWindow fyne.Window) *widget.Button {
+	button := widget.NewButton("Alerts", func() {
+		showNotificationCenter(env, parentWindow)
+	})
+	workers.Go(ctx, "unread notifications", func(ctx context.Context) {
+		for {
+			changed := notificationsState()
+			unread, err := notifications.Unread(env.DB)
+			if err != nil {
+				log.Err(err).Msg("failed counting unread notifications")
+			}
+			if unread > 0 {
+				button.SetText("Alerts (" + fmt.Sprint(unread) + ")")
+				button.Importance = widget.HighImportance
+			} else {
+				button.SetText("Alerts")
+				button.Importance = widget.MediumImportance
+			}
+			button.Refresh()
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+		}
+	})
+	return button
+}
+
+// NotificationCenterWidget lists the past alerts and lets the user choose
+// which events they are alerted about.
+type NotificationCenterWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+func NewNotificationCenterWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *NotificationCenterWidget {
+	w := &NotificationCenterWidget{}
+	w.ExtendBaseWidget(w)
+
+	list := container.NewVBox()
+	update := func() {
+		past, err := notifications.List(env.DB)
+		if err != nil {
+			log.Err(err).Msg("failed listing notifications")
+			dialog.ShowError(err, parentWindow)
+			return
+		}
+		list.RemoveAll()
+		if len(past) == 0 {
+			list.Add(widget.NewLabel("No alerts yet"))
+		}
+		for _, notification := range past {
+			heading := notification.CreatedAt.Format("15:04") + " " + notification.Title
+			list.Add(widget.NewLabelWithStyle(heading, fyne.TextAlignLeading, fyne.TextStyle{Bold: !notification.Read}))
+			list.Add(widget.NewLabel(notification.Content))
+		}
+	}
+
+	toggles := container.NewVBox()
+	for _, kind := range notifications.Kinds {
+		toggle := widget.NewCheck(notificationKindNames[kind], func(enabled bool) {
+			err := notifications.SetEnabled(env.DB, kind, enabled)
+			if err != nil {
+				log.Err(err).Msg("failed saving notification setting")
+				dialog.ShowError(err, parentWindow)
+			}
+		})
+		toggle.Checked = notifications.Enabled(env.DB, kind)
+		toggles.Add(toggle)
+	}
+
+	clearButton := widget.NewButton("Clear alerts", func() {
+		err := notifications.Clear(env.DB)
+		if err != nil {
+			log.Err(err).Msg("failed clearing notifications")
+			dialog.ShowError(err, parentWindow)
+			return
+		}
+		notificationsUpdated()
+	})
+
+	w.content = container.NewBorder(
+		widget.NewCard("Alert me about", "", toggles),
+		clearButton, nil, nil,
+		container.NewVScroll(list),
+	)
+
+	workers.Go(ctx, "notification center", func(ctx context.Context) {
+		for {
+			changed := notificationsState()
+			update()
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+		}
+	})
+
+	return w
+}
+
+func (w *NotificationCenterWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+// showNotificationCenter shows the notification center, the alerts count as
+// seen once it is closed.
+func showNotificationCenter(env env.Env, parentWindow fyne.Window) {
+	ctx, cancel := context.WithCancel(context.Background())
+	centerDialog := dialog.NewCustom("Alerts", "Close", NewNotificationCenterWidget(ctx, env, parentWindow), parentWindow)
+	centerDialog.SetOnClosed(func() {
+		cancel()
+		err := notifications.MarkAllRead(env.DB)
+		if err != nil {
+			log.Err(err).Msg("failed marking notifications as read")
+		}
+		notificationsUpdated()
+	})
+	centerDialog.Resize(fyne.NewSize(400, 600))
+	centerDialog.Show()
+}
@@ -0,0 +1,179 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/router"
+	"github.com/jkulzer/fib-client/state"
+)
+
+var ErrPasswordsDiffer = errors.New("The new passwords don't match")
+
+type ProfileWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewProfileWidget shows the account of the user with their stats and lets
+// them change the password, log out or delete the account.
+func NewProfileWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *ProfileWidget {
+	w := &ProfileWidget{}
+	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
+
+	backButton := widget.NewButton("Back", func() {
+		RouterFor(parentWindow).Back()
+	})
+	logoutButton := widget.NewButton("Logout", func() {
+		confirmLogout(env, parentWindow)
+	})
+	top := container.NewHBox(backButton, logoutButton, newAccountsButton(parentWindow))
+
+	usernameLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	memberSinceLabel := widget.NewLabel("")
+	statsLabel := widget.NewLabel("Loading stats...")
+
+	oldPasswordEntry := widget.NewPasswordEntry()
+	newPasswordEntry := widget.NewPasswordEntry()
+	newPasswordEntry.Validator = validation.NewRegexp("^.{8,32}$", "Password must be at least 8 or at most 32 characters long")
+	repeatPasswordEntry := widget.NewPasswordEntry()
+	passwordForm := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Current password", Widget: oldPasswordEntry},
+			{Text: "New password", Widget: newPasswordEntry},
+			{Text: "Repeat new password", Widget: repeatPasswordEntry},
+		},
+		OnSubmit: func() {
+			if newPasswordEntry.Text != repeatPasswordEntry.Text {
+				dialog.ShowError(ErrPasswordsDiffer, parentWindow)
+				return
+			}
+			go func() {
+				err := api.ChangePassword(context.Background(), oldPasswordEntry.Text, newPasswordEntry.Text)
+				if err != nil {
+					showRequestError(err, parentWindow)
+					return
+				}
+				log.Info().Msg("changed password")
+				oldPasswordEntry.SetText("")
+				newPasswordEntry.SetText("")
+				repeatPasswordEntry.SetText("")
+				dialog.ShowInformation("Password", "Your password was changed. All other devices were logged out.", parentWindow)
+			}()
+		},
+		SubmitText: "Change password",
+	}
+
+	deleteButton := widget.NewButton("Delete account", func() {
+		confirmDeleteAccount(env, parentWindow)
+	})
+	deleteButton.Importance = widget.DangerImportance
+
+	w.content = container.NewBorder(top, nil, nil, nil, container.NewVScroll(container.NewVBox(
+		usernameLabel,
+		memberSinceLabel,
+		widget.NewCard("Stats", "", statsLabel),
+		widget.NewCard("Change password", "", passwordForm),
+		deleteButton,
+	)))
+
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err == nil {
+		usernameLabel.SetText(loginInfo.Username)
+	}
+
+	profile, err := api.GetProfile(ctx)
+	if err != nil {
+		log.Err(err).Msg("failed getting profile")
+		statsLabel.SetText("Stats aren't available right now.")
+		return w
+	}
+	usernameLabel.SetText(profile.Username)
+	memberSinceLabel.SetText("Member since " + profile.CreatedAt.Format(time.DateOnly))
+	statsLabel.SetText(fmt.Sprintf(
+		"Games played: %d\nAs hider: %d\nAs seeker: %d\nLongest hiding time: %s",
+		profile.Stats.GamesPlayed,
+		profile.Stats.GamesAsHider,
+		profile.Stats.GamesAsSeeker,
+		profile.Stats.LongestHidingTime.Truncate(time.Second),
+	))
+
+	return w
+}
+
+func (w *ProfileWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+// newProfileButton opens the profile screen.
+func newProfileButton(parentWindow fyne.Window) *widget.Button {
+	return widget.NewButton("Profile", func() {
+		RouterFor(parentWindow).Navigate(router.Profile)
+	})
+}
+
+func confirmLogout(env env.Env, parentWindow fyne.Window) {
+	dialog.ShowConfirm("Logout", "Are you sure you want to log out?", func(confirmed bool) {
+		if confirmed {
+			go logOut(env, parentWindow)
+		}
+	}, parentWindow)
+}
+
+// logOut ends the session on the server and forgets it locally. The local
+// logout happens even if the server can't be reached, the session then
+// simply runs out.
+func logOut(env env.Env, parentWindow fyne.Window) {
+	state.CloseAll()
+	err := client.NewAPI(env).Logout(context.Background())
+	if err != nil {
+		log.Warn().Msg("failed ending session on the server: " + fmt.Sprint(err))
+	}
+	err = helpers.DeleteAppConfig(env)
+	if err != nil {
+		log.Err(err).Msg(fmt.Sprint(err))
+		dialog.ShowError(err, parentWindow)
+	}
+	RouterFor(parentWindow).Sync()
+}
+
+func confirmDeleteAccount(env env.Env, parentWindow fyne.Window) {
+	passwordEntry := widget.NewPasswordEntry()
+	items := []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel("This deletes your account and all your games for good.\nEnter your password to confirm.")),
+		{Text: "Password", Widget: passwordEntry},
+	}
+	dialog.ShowForm("Delete account", "Delete", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		go func() {
+			err := client.NewAPI(env).DeleteAccount(context.Background(), passwordEntry.Text)
+			if err != nil {
+				showRequestError(err, parentWindow)
+				return
+			}
+			log.Info().Msg("deleted account")
+			state.CloseAll()
+			err = helpers.DeleteAppConfig(env)
+			if err != nil {
+				log.Err(err).Msg(fmt.Sprint(err))
+			}
+			RouterFor(parentWindow).Sync()
+		}()
+	}, parentWindow)
+}
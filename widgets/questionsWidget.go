@@ -9,6 +9,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"context"
 	"fmt"
 	"strconv"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/location"
 	"github.com/jkulzer/fib-client/mapWidget"
+	"github.com/jkulzer/fib-client/notifications"
 )
 
 type QuestionWidget struct {
@@ -25,10 +27,15 @@ type QuestionWidget struct {
 	content *fyne.Container
 }
 
-func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *mapWidget.Map, historyWidgetPointer *HistoryWidget) *QuestionWidget {
+// NewQuestionWidget lists the questions seekers can ask. In the endgame the
+// endgame questions come first. The cooldowns are shared by the seeker team.
+func NewQuestionWidget(ctx context.Context, env env.Env, parentWindow fyne.Window, mapWidgetPointer *mapWidget.Map, historyWidgetPointer *HistoryWidget, endgame bool) *QuestionWidget {
 	w := &QuestionWidget{}
 	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
 	w.content = container.NewVBox()
+	settings := lobbySettings(ctx, env)
+	cooldownLabels := newCooldownLabels(ctx, env)
 
 	setLocationButton := widget.NewButton("Set Location", func() {
 		go func() {
@@ -38,19 +45,22 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 				dialog.ShowError(err, parentWindow)
 				return
 			}
-			err = client.SaveLocation(env, parentWindow, locationPoint)
+			err = api.SaveLocation(context.Background(), locationPoint)
 			if err != nil {
-				dialog.ShowError(err, parentWindow)
+				showRequestError(err, parentWindow)
 			}
 		}()
 	})
 	w.content.Add(setLocationButton)
+	endgameQuestions := newEndgameQuestions(api, parentWindow, mapWidgetPointer, historyWidgetPointer)
+	if endgame {
+		w.content.Add(endgameQuestions)
+	}
 	var questionHeaderSize float32 = 18.0
 
-	matchingText := canvas.NewText("Ja/Nein Fragen:", theme.Color(theme.ColorNameForeground))
+	matchingText := canvas.NewText(categoryNames[client.CategoryMatching]+":", theme.Color(theme.ColorNameForeground))
 	matchingText.TextSize = questionHeaderSize // Big font size
 	matchingText.TextStyle = fyne.TextStyle{Bold: true}
-	w.content.Add(matchingText)
 	// question grid
 	matchingButtonsContainer := container.NewGridWithColumns(2)
 
@@ -59,9 +69,9 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 	matchingButtonsContainer.Add(widget.NewButton(buttonName, func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question "+buttonName, func(confirmed bool) {
 			if confirmed {
-				err := client.AskSameBezirk(env, parentWindow)
+				err := api.AskSameBezirk(context.Background())
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 					return
 				}
 				log.Debug().Msg("asked same bezirk")
@@ -73,9 +83,9 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 	matchingButtonsContainer.Add(widget.NewButton(buttonName, func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question "+buttonName, func(confirmed bool) {
 			if confirmed {
-				err := client.AskSameOrtsteil(env, parentWindow)
+				err := api.AskSameOrtsteil(context.Background())
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 					return
 				}
 				log.Debug().Msg("asked same ortsteil")
@@ -87,9 +97,9 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 	matchingButtonsContainer.Add(widget.NewButton(buttonName, func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question "+buttonName, func(confirmed bool) {
 			if confirmed {
-				err := client.AskOrtsteilLastLetter(env, parentWindow)
+				err := api.AskOrtsteilLastLetter(context.Background())
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 					return
 				}
 				log.Debug().Msg("asked ortsteil last letter question")
@@ -102,10 +112,10 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question "+buttonName, func(confirmed bool) {
 			if confirmed {
 				log.Info().Msg("asked train service question")
-				closeRouteList, err := client.GetCloseRoutes(env, parentWindow)
+				closeRouteList, err := api.GetCloseRoutes(context.Background())
 				if err != nil {
 					log.Err(err).Msg("failed asking train service question")
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 					return
 				}
 				var trainSelectDialog *dialog.CustomDialog
@@ -113,10 +123,10 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 				for _, route := range closeRouteList.Routes {
 					routeSelectionButton := widget.NewButton(route.Name, func() {
 						log.Info().Msg("selected route " + route.Name + " with ID " + fmt.Sprint(route.RouteID))
-						err := client.AskTrainservice(env, parentWindow, route.RouteID)
+						err := api.AskTrainservice(context.Background(), route.RouteID)
 						if err != nil {
 							log.Err(err).Msg("failed asking train service question")
-							dialog.ShowError(err, parentWindow)
+							showRequestError(err, parentWindow)
 							return
 						}
 						trainSelectDialog.Hide()
@@ -135,21 +145,23 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 		}, parentWindow)
 	}))
 	// add matching questions container
-	w.content.Add(matchingButtonsContainer)
+	if settings.Allows(client.CategoryMatching) {
+		w.content.Add(matchingText)
+		w.content.Add(cooldownLabels[client.CategoryMatching])
+		w.content.Add(matchingButtonsContainer)
+	}
 
-	relativeText := canvas.NewText("Relative Fragen:", theme.Color(theme.ColorNameForeground))
+	relativeText := canvas.NewText(categoryNames[client.CategoryRelative]+":", theme.Color(theme.ColorNameForeground))
 	relativeText.TextSize = 18 // Big font size
 	relativeText.TextStyle = fyne.TextStyle{Bold: true}
-	w.content.Add(relativeText)
-	w.content.Add(widget.NewLabel("Näher oder weiter weg von..."))
 	relativeButtonsContainer := container.NewGridWithColumns(2)
 	buttonName = "...einem McDonald's?"
 	relativeButtonsContainer.Add(widget.NewButton(buttonName, func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 			if confirmed {
-				err := client.AskQuestion(env, parentWindow, "closerToMcDonalds", "McDonald's Distance")
+				err := api.AskQuestion(context.Background(), "closerToMcDonalds", "McDonald's Distance")
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 				}
 				refreshMap(mapWidgetPointer, historyWidgetPointer)
 			}
@@ -159,9 +171,9 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 	relativeButtonsContainer.Add(widget.NewButton(buttonName, func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 			if confirmed {
-				err := client.AskQuestion(env, parentWindow, "closerToIkea", "IKEA Distance")
+				err := api.AskQuestion(context.Background(), "closerToIkea", "IKEA Distance")
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 				}
 				refreshMap(mapWidgetPointer, historyWidgetPointer)
 			}
@@ -170,75 +182,68 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 	relativeButtonsContainer.Add(widget.NewButton("...der Spree?", func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 			if confirmed {
-				err := client.AskQuestion(env, parentWindow, "closerToSpree", "Spree Distance")
+				err := api.AskQuestion(context.Background(), "closerToSpree", "Spree Distance")
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 				}
 				refreshMap(mapWidgetPointer, historyWidgetPointer)
 			}
 		}, parentWindow)
 	}))
-	w.content.Add(relativeButtonsContainer)
+	if settings.Allows(client.CategoryRelative) {
+		w.content.Add(relativeText)
+		w.content.Add(cooldownLabels[client.CategoryRelative])
+		w.content.Add(widget.NewLabel("Näher oder weiter weg von..."))
+		w.content.Add(relativeButtonsContainer)
+	}
 
-	thermometerText := canvas.NewText("Thermometer:", theme.Color(theme.ColorNameForeground))
+	thermometerText := canvas.NewText(categoryNames[client.CategoryThermometer]+":", theme.Color(theme.ColorNameForeground))
 	thermometerText.TextSize = 18 // Big font size
 	thermometerText.TextStyle = fyne.TextStyle{Bold: true}
-	w.content.Add(thermometerText)
 	// question grid
 	thermometerButtonsContainer := container.NewGridWithColumns(2)
 
 	thermometerButtonsContainer.Add(widget.NewButton("Starte Thermometer", func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 			if confirmed {
-				err := client.StartThermometer(env, parentWindow, 100)
+				err := api.StartThermometer(context.Background(), 100)
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
+					return
 				}
+				madeOwnChange(notifications.KindThermometer)
 			}
 		}, parentWindow)
 	}))
 	thermometerButtonsContainer.Add(widget.NewButton("Ende Thermometer", func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 			if confirmed {
-				err := client.EndThermometer(env, parentWindow)
+				err := api.EndThermometer(context.Background())
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 					return
 				}
 				refreshMap(mapWidgetPointer, historyWidgetPointer)
 			}
 		}, parentWindow)
 	}))
-	w.content.Add(thermometerButtonsContainer)
+	if settings.Allows(client.CategoryThermometer) {
+		w.content.Add(thermometerText)
+		w.content.Add(cooldownLabels[client.CategoryThermometer])
+		w.content.Add(thermometerButtonsContainer)
+	}
 
 	// Radar questions
-	radarText := canvas.NewText("Radar:", theme.Color(theme.ColorNameForeground))
+	radarText := canvas.NewText(categoryNames[client.CategoryRadar]+":", theme.Color(theme.ColorNameForeground))
 	radarText.TextSize = 18 // Big font size
 	radarText.TextStyle = fyne.TextStyle{Bold: true}
-	w.content.Add(radarText)
-	// question grid
+	// question grid, with the radii of the lobby
 	radarButtonsContainer := container.NewGridWithColumns(2)
-	radarButtonsContainer.Add(widget.NewButton("200m Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 200, mapWidgetPointer, historyWidgetPointer)
-	}))
-	radarButtonsContainer.Add(widget.NewButton("500m Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 500, mapWidgetPointer, historyWidgetPointer)
-	}))
-	radarButtonsContainer.Add(widget.NewButton("1km Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 1000, mapWidgetPointer, historyWidgetPointer)
-	}))
-	radarButtonsContainer.Add(widget.NewButton("2.5km Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 2500, mapWidgetPointer, historyWidgetPointer)
-	}))
-	radarButtonsContainer.Add(widget.NewButton("5km Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 5000, mapWidgetPointer, historyWidgetPointer)
-	}))
-	radarButtonsContainer.Add(widget.NewButton("10km Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 10000, mapWidgetPointer, historyWidgetPointer)
-	}))
-	radarButtonsContainer.Add(widget.NewButton("15km Radar", func() {
-		AskRadarWithRadius(env, parentWindow, 15000, mapWidgetPointer, historyWidgetPointer)
-	}))
+	for _, radius := range settings.RadarRadii {
+		radarButtonsContainer.Add(widget.NewButton(radiusName(radius)+" Radar", func() {
+			AskRadarWithRadius(env, parentWindow, radius, mapWidgetPointer, historyWidgetPointer)
+		}))
+	}
 	radarButtonsContainer.Add(widget.NewButton("??? Radar", func() {
 		// TODO
 		radiusEntry := widget.NewEntry()
@@ -259,28 +264,37 @@ func NewQuestionWidget(env env.Env, parentWindow fyne.Window, mapWidgetPointer *
 		formDialog.Show()
 	}))
 	// add radar questions container
-	w.content.Add(radarButtonsContainer)
+	if settings.Allows(client.CategoryRadar) {
+		w.content.Add(radarText)
+		w.content.Add(cooldownLabels[client.CategoryRadar])
+		w.content.Add(radarButtonsContainer)
+	}
+
+	if !endgame {
+		w.content.Add(endgameQuestions)
+	}
+
+	w.content = container.NewStack(container.NewVScroll(w.content))
+	return w
+}
 
+func newEndgameQuestions(api *client.API, parentWindow fyne.Window, mapWidgetPointer *mapWidget.Map, historyWidgetPointer *HistoryWidget) *fyne.Container {
 	endgameQuestionsText := canvas.NewText("Endgame questions:", theme.Color(theme.ColorNameForeground))
 	endgameQuestionsText.TextSize = 18 // Big font size
 	endgameQuestionsText.TextStyle = fyne.TextStyle{Bold: true}
-	w.content.Add(endgameQuestionsText)
 	endgameQuestionsContainer := container.NewGridWithColumns(2)
 	endgameQuestionsContainer.Add(widget.NewButton("Hiding zone", func() {
 		dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 			if confirmed {
-				err := client.AskQuestion(env, parentWindow, "isInHidingZone", "In hiding zone")
+				err := api.AskQuestion(context.Background(), "isInHidingZone", "In hiding zone")
 				if err != nil {
-					dialog.ShowError(err, parentWindow)
+					showRequestError(err, parentWindow)
 				}
 				refreshMap(mapWidgetPointer, historyWidgetPointer)
 			}
 		}, parentWindow)
 	}))
-	w.content.Add(endgameQuestionsContainer)
-
-	w.content = container.NewStack(container.NewVScroll(w.content))
-	return w
+	return container.NewVBox(endgameQuestionsText, endgameQuestionsContainer)
 }
 
 func (w *QuestionWidget) CreateRenderer() fyne.WidgetRenderer {
@@ -290,10 +304,10 @@ func (w *QuestionWidget) CreateRenderer() fyne.WidgetRenderer {
 func AskRadarWithRadius(env env.Env, parentWindow fyne.Window, radius float64, mapWidgetPointer *mapWidget.Map, historyWidgetPointer *HistoryWidget) {
 	dialog.ShowConfirm("Ask question", "Are you sure you want to ask the question?", func(confirmed bool) {
 		if confirmed {
-			err := client.AskRadar(env, parentWindow, radius)
+			err := client.NewAPI(env).AskRadar(context.Background(), radius)
 			fmt.Println("asked radar with radius", radius)
 			if err != nil {
-				dialog.ShowError(err, parentWindow)
+				showRequestError(err, parentWindow)
 				return
 			}
 			refreshMap(mapWidgetPointer, historyWidgetPointer)
@@ -8,98 +8,202 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/router"
+	"github.com/jkulzer/fib-client/workers"
 
 	"github.com/jkulzer/fib-server/sharedModels"
 )
 
+// how often the pre-start countdown is redrawn
+const startCountdownInterval = 100 * time.Millisecond
+
 type ReadinessWidget struct {
 	widget.BaseWidget
 	content *fyne.Container
 }
 
-func NewReadinessWidget(env env.Env, parentWindow fyne.Window) *ReadinessWidget {
+// NewReadinessWidget lists the players of the lobby with their roles and
+// whether they are ready. Once everyone is ready the server starts a short
+// countdown, shown to all players at the same time, during which anyone can
+// still cancel their readiness.
+func NewReadinessWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *ReadinessWidget {
 	w := &ReadinessWidget{}
 	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
 	w.content = container.NewVBox()
 
-	appConfig, err := helpers.GetAppConfig(env, parentWindow)
+	loginInfo, err := helpers.GetAppConfig(env)
 	if err != nil {
-		log.Err(err).Msg(fmt.Sprint(err))
+		log.Err(err).Msg("failed to get app config in readiness")
 		dialog.ShowError(err, parentWindow)
-		w.content = container.NewVBox(widget.NewLabel("failed to load app config"))
 		return w
 	}
 
-	readiness, err := client.IsLobbyComplete(env, parentWindow)
+	readiness, err := api.IsLobbyComplete(ctx)
 	if err != nil {
 		errorMessage := fmt.Sprint(err)
 		log.Err(err).Msg(errorMessage)
-		dialog.ShowError(err, parentWindow)
+		showRequestError(err, parentWindow)
 		w.content.Add(widget.NewLabel(errorMessage))
 		return w
 	}
 
-	if readiness {
-		w.content.Add(container.NewVBox(widget.NewLabel("ready to start")))
-		log.Info().Msg("lobby is ready to start")
-	} else {
-		w.content.Add(container.NewVBox(widget.NewLabel("Waiting for other players...")))
-		log.Info().Msg("lobby not ready")
+	// the readiness of the user the server knows, or was last sent
+	var readyMu sync.Mutex
+	serverReady := false
+	var setChecked func(ready bool)
+	readinessSelector := widget.NewCheck("Ready", func(readySelected bool) {
+		readyMu.Lock()
+		known := readySelected == serverReady
+		serverReady = readySelected
+		readyMu.Unlock()
+		if known {
+			// set by setChecked, the server already knows
+			return
+		}
+		go func() {
+			err := api.SetReadiness(context.Background(), readySelected)
+			if err != nil {
+				showRequestError(err, parentWindow)
+				if !errors.Is(err, client.ErrQueued) {
+					setChecked(!readySelected)
+				}
+			}
+		}()
+	})
+	// setChecked shows the readiness the server knows without sending it back
+	setChecked = func(ready bool) {
+		readyMu.Lock()
+		serverReady = ready
+		readyMu.Unlock()
+		readinessSelector.SetChecked(ready)
 	}
 
-	readinessSelector := widget.NewCheck("Ready", func(readySelected bool) {
-		err := client.SetReadiness(env, parentWindow, readySelected)
-		if err != nil {
-			log.Err(err).Msg(fmt.Sprint(err))
-			dialog.ShowError(err, parentWindow)
+	statusLabel := widget.NewLabel("")
+	setReadiness := func(readiness bool) {
+		if readiness {
+			statusLabel.SetText("ready to start")
+			log.Info().Msg("lobby is ready to start")
+			// the lobby is only ready once everyone is, servers without lobby
+			// details don't tell the readiness of the user otherwise
+			setChecked(true)
+		} else {
+			statusLabel.SetText("Waiting for other players...")
+			log.Info().Msg("lobby not ready")
 		}
+	}
+	setReadiness(readiness)
+
+	playersList := container.NewVBox()
+	readyCountLabel := widget.NewLabel("")
+
+	cancelButton := widget.NewButton("Not ready, stop the countdown", func() {
+		readinessSelector.SetChecked(false)
 	})
+	cancelButton.Importance = widget.DangerImportance
+	cancelButton.Hide()
+
+	startCountdown := NewCountdown(ctx, "start countdown", CountDown, startCountdownInterval)
+	startCountdown.SetTextSize(32)
+	startCountdown.SetFormat(func(left time.Duration) string {
+		return "Game starts in " + fmt.Sprint(int(left.Seconds())+1)
+	})
+	// the router switches to the run phase once the game starts
+	startCountdown.SetFinished("Starting...", cancelButton.Hide)
+	startCountdown.Hide()
+	showCountdown := func(startsAt *time.Time) {
+		if startsAt == nil {
+			startCountdown.SetTarget(time.Time{})
+			startCountdown.Hide()
+			cancelButton.Hide()
+			return
+		}
+		startCountdown.Show()
+		cancelButton.Show()
+		startCountdown.SetTarget(*startsAt)
+	}
+
+	setDetails := func(details client.LobbyDetails) {
+		playersList.RemoveAll()
+		for _, player := range details.Players {
+			readiness := "not ready"
+			if player.Ready {
+				readiness = "ready"
+			}
+			name := player.Username
+			if name == loginInfo.Username {
+				name += " (you)"
+			}
+			playersList.Add(widget.NewLabel(name + ": " + roleNames[player.Role] + ", " + readiness))
+		}
+		readyCountLabel.SetText(fmt.Sprint(details.ReadyCount()) + " of " + fmt.Sprint(len(details.Players)) + " players ready")
+		if me, ok := details.Player(loginInfo.Username); ok {
+			setChecked(me.Ready)
+		}
+		showCountdown(details.StartsAt)
+	}
+
+	details, err := api.GetLobbyDetails(ctx, loginInfo.LobbyToken)
+	if err != nil {
+		// servers without lobby details only tell whether everyone is ready
+		log.Warn().Msg("couldn't get the players of the lobby: " + fmt.Sprint(err))
+		playersList.Add(widget.NewLabel("The players can't be shown right now"))
+	} else {
+		setDetails(details)
+	}
+
+	w.content.Add(container.NewVBox(statusLabel))
+	w.content.Add(startCountdown)
+	w.content.Add(cancelButton)
+	w.content.Add(widget.NewCard("Players", "", container.NewVBox(readyCountLabel, playersList)))
+	w.content.Add(widget.NewCard("Rules of this lobby", "", widget.NewLabel(settingsSummary(lobbySettings(ctx, env)))))
 	w.content.Add(readinessSelector)
+	w.content.Add(widget.NewButton("Change role", func() {
+		RouterFor(parentWindow).Navigate(router.RoleSelect)
+	}))
+	w.content.Add(NewHostPanelWidget(ctx, env, parentWindow))
 
 	log.Info().Msg("created start phase widget")
 
-	go func() {
-		for {
-			timer1 := time.NewTimer(2 * time.Second)
+	events, err := api.Subscribe(ctx, client.EventReadiness, client.EventLobby)
+	if err != nil {
+		log.Err(err).Msg("failed subscribing to readiness events")
+		showRequestError(err, parentWindow)
+		return w
+	}
 
-			<-timer1.C
-			readiness, err := client.IsLobbyComplete(env, parentWindow)
-			if err != nil {
-				errorMessage := fmt.Sprint(err)
-				log.Err(err).Msg(errorMessage)
-				dialog.ShowError(err, parentWindow)
-				w.content.Add(widget.NewLabel(errorMessage))
-				return
-			}
-			if readiness == true {
-				switch appConfig.Role {
-				case sharedModels.Hider:
-					hiderRunPhaseWidget := NewHiderRunPhaseWidget(env, parentWindow)
-					gameFrame := NewGameFrameWidget(env, parentWindow, hiderRunPhaseWidget)
-					parentWindow.SetContent(gameFrame)
-					return
-				case sharedModels.Seeker:
-					seekerRunPhaseWidget := NewSeekerRunPhaseWidget(env, parentWindow)
-					gameFrame := NewGameFrameWidget(env, parentWindow, seekerRunPhaseWidget)
-					parentWindow.SetContent(gameFrame)
-					return
-				default:
-					message := "You are in a lobby without a valid role. Join a different one"
-					err := errors.New(message)
-					log.Err(err).Msg(message)
-					dialog.ShowError(err, parentWindow)
-					return
+	workers.Go(ctx, "readiness listener", func(ctx context.Context) {
+		for event := range events {
+			switch event.Type {
+			case client.EventReadiness:
+				var readinessResponse sharedModels.ReadinessResponse
+				err := event.Decode(&readinessResponse)
+				if err != nil {
+					log.Err(err).Msg("failed decoding readiness event")
+					continue
 				}
+				// the router switches to the run phase once the game starts
+				setReadiness(readinessResponse.Ready)
+			case client.EventLobby:
+				var details client.LobbyDetails
+				err := event.Decode(&details)
+				if err != nil {
+					log.Err(err).Msg("failed decoding lobby event")
+					continue
+				}
+				setDetails(details)
 			}
 		}
-	}()
+	})
 
 	return w
 }
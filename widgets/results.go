@@ -0,0 +1,121 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/mapWidget"
+	"github.com/jkulzer/fib-client/state"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+type ResultsWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewResultsWidget shows the summary of a finished game to both roles.
+func NewResultsWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *ResultsWidget {
+	w := &ResultsWidget{}
+	w.ExtendBaseWidget(w)
+	api := client.NewAPI(env)
+
+	var rematchButton *widget.Button
+	rematchButton = widget.NewButton("Rematch", func() {
+		rematchButton.Disable()
+		go func() {
+			defer rematchButton.Enable()
+			lobby, err := api.Rematch(context.Background())
+			if err != nil {
+				log.Err(err).Msg("failed starting rematch")
+				showRequestError(err, parentWindow)
+				return
+			}
+			log.Info().Msg("starting rematch in lobby " + lobby.LobbyToken)
+			switchLobby(lobby.LobbyToken, sharedModels.NoRole, parentWindow, env)
+		}()
+	})
+	returnButton := widget.NewButton("Return to lobby selection", func() {
+		switchLobby("", sharedModels.NoRole, parentWindow, env)
+	})
+	buttons := container.NewHBox(rematchButton, returnButton)
+	w.content = container.NewBorder(nil, buttons, nil, nil)
+
+	results, err := api.GetResults(ctx)
+	if err != nil {
+		log.Err(err).Msg("failed getting game results")
+		showRequestError(err, parentWindow)
+		return w
+	}
+
+	store, err := state.For(env)
+	if err != nil {
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	err = store.RefreshMap(ctx)
+	if err != nil {
+		showRequestError(err, parentWindow)
+		return w
+	}
+
+	settings := lobbySettings(ctx, env)
+	hidingTime := results.HidingTime(settings.RunDuration).Truncate(time.Second)
+	summary := container.NewVBox(
+		widget.NewLabelWithStyle("Game over", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Total hiding time: "+fmt.Sprint(hidingTime)),
+		widget.NewLabel("Questions asked: "+fmt.Sprint(len(results.History))),
+		widget.NewLabel("Cards played: "+fmt.Sprint(len(results.CardsPlayed))),
+		widget.NewLabel("Curses cast: "+fmt.Sprint(len(results.CursesCast))),
+		widget.NewLabel(settingsSummary(settings)),
+		widget.NewLabel("The map shows the hiding zone in green\nand the area the seekers narrowed down in blue."),
+	)
+
+	resultsMap := mapWidget.NewMapWithOptions(ctx, env, &parentWindow, mapWidget.WithHighlight(results.HidingZone, hidingZoneColor))
+
+	history := widget.NewAccordion()
+	for _, item := range results.History {
+		history.Append(widget.NewAccordionItem(historyItemTitle(item), widget.NewLabel(item.Description)))
+	}
+
+	cards := widget.NewAccordion()
+	cards.Append(widget.NewAccordionItem("Cards played ("+fmt.Sprint(len(results.CardsPlayed))+")", cardList(results.CardsPlayed)))
+	cards.Append(widget.NewAccordionItem("Curses cast ("+fmt.Sprint(len(results.CursesCast))+")", cardList(results.CursesCast)))
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Summary", summary),
+		container.NewTabItem("Map", resultsMap),
+		container.NewTabItem("History", container.NewScroll(history)),
+		container.NewTabItem("Cards", container.NewScroll(cards)),
+	)
+	tabs.SetTabLocation(container.TabLocationBottom)
+	w.content = container.NewBorder(nil, buttons, nil, nil, tabs)
+
+	return w
+}
+
+func cardList(cards []sharedModels.Card) fyne.CanvasObject {
+	list := container.NewVBox()
+	for _, card := range cards {
+		title := widget.NewLabelWithStyle(card.Title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		list.Add(container.NewVBox(title, widget.NewLabel(card.Description)))
+	}
+	if len(cards) == 0 {
+		list.Add(widget.NewLabel("None"))
+	}
+	return list
+}
+
+func (w *ResultsWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
@@ -6,17 +6,19 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"net/http"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/helpers"
 	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/router"
+	"github.com/jkulzer/fib-client/state"
+	"github.com/jkulzer/fib-client/workers"
 	"github.com/jkulzer/fib-server/sharedModels"
 )
 
@@ -25,95 +27,100 @@ type RoleSelectionWidget struct {
 	content *fyne.Container
 }
 
-func NewRoleSelectionWidget(env env.Env, parentWindow fyne.Window, validatedLobbyToken string) *RoleSelectionWidget {
+// NewRoleSelectionWidget lets the user become the hider or join the seeker
+// team, showing who already took which role. The roles are updated as other
+// players take or release them. Before the game starts a user holding a role
+// can switch to another one or release it.
+func NewRoleSelectionWidget(ctx context.Context, env env.Env, parentWindow fyne.Window, validatedLobbyToken string) *RoleSelectionWidget {
 	w := &RoleSelectionWidget{}
 	w.ExtendBaseWidget(w)
-	w.content = container.NewVBox()
-	req, err := http.NewRequest("GET", env.Url+"/lobby/"+validatedLobbyToken+"/roles", nil)
+	api := client.NewAPI(env)
+	slotsContainer := container.NewVBox()
+	w.content = container.NewVBox(slotsContainer, NewHostPanelWidget(ctx, env, parentWindow))
+
+	appConfig, err := helpers.GetAppConfig(env)
 	if err != nil {
+		log.Err(err).Msg("failed to get app config in role selection")
 		dialog.ShowError(err, parentWindow)
+		return w
 	}
-	loginInfo, err := helpers.GetAppConfig(env, parentWindow)
-	if err == nil {
-		req.Header.Add("Authorization", "Bearer "+loginInfo.Token.String())
-		res, err := http.DefaultClient.Do(req)
+	selectRole := func(role sharedModels.UserRole) {
+		err := HandleRoleSelection(env, validatedLobbyToken, parentWindow, appConfig, role)
 		if err != nil {
-			log.Warn().Msg("couldn't make request" + fmt.Sprint(err))
-			dialog.ShowError(err, parentWindow)
+			RouterFor(parentWindow).Navigate(router.Lobby)
 		} else {
-			switch res.StatusCode {
-			case http.StatusOK:
-				var responseStruct sharedModels.RoleAvailability
-				responseBytes, err := helpers.ReadHttpResponse(res.Body)
-				if err != nil {
-					log.Err(err).Msg("failed to read http response for role selection")
-					dialog.ShowError(err, parentWindow)
-				}
-				err = json.Unmarshal(responseBytes, &responseStruct)
-				if err != nil {
-					message := "Failed to unmarshal http response"
-					log.Err(err).Msg(message)
-					dialog.ShowError(err, parentWindow)
-				}
-
-				w.content = container.NewVBox()
-				if len(responseStruct) == 0 {
-					w.content.Add(widget.NewLabel("No roles available, lobby full"))
-				} else {
-					for _, role := range responseStruct {
-						var button *widget.Button
-						appConfig, err := helpers.GetAppConfig(env, parentWindow)
-						if err != nil {
-							log.Err(err).Msg("failed to get app config in role selection button for loop")
-							dialog.ShowError(err, parentWindow)
-							break
-						} else {
-							if role == sharedModels.Hider {
-								button = widget.NewButton("Hider", func() {
-									log.Info().Msg("chose hider role")
-									err := HandleRoleSelection(env, validatedLobbyToken, parentWindow, appConfig, role)
-									if err != nil {
-										parentWindow.SetContent(container.NewVBox(NewLobbySelectionWidget(env, parentWindow)))
-									} else {
-										center := NewHiderWidget(env, parentWindow)
-										gameFrame := NewGameFrameWidget(env, parentWindow, center)
-										parentWindow.SetContent(gameFrame)
-									}
-								})
-							} else if role == sharedModels.Seeker {
-								button = widget.NewButton("Seeker", func() {
-									log.Info().Msg("chose seeker role")
-									err := HandleRoleSelection(env, validatedLobbyToken, parentWindow, appConfig, role)
-									if err != nil {
-										parentWindow.SetContent(container.NewVBox(NewLobbySelectionWidget(env, parentWindow)))
-									} else {
-										center := NewSeekerWidget(env, parentWindow)
-										gameFrame := NewGameFrameWidget(env, parentWindow, center)
-										parentWindow.SetContent(gameFrame)
-									}
-								})
-							} else {
-								message := "Reached unreachable state in role selection"
-								log.Err(nil).Msg(message)
-								w.content = container.NewVBox(widget.NewLabel(message))
-								return w
-							}
-							w.content.Add(container.NewVBox(button))
-						}
-					}
-				}
-
-			case http.StatusForbidden:
-				message := "Not logged in. Log out and back in."
-				log.Info().Msg(message)
-				error := errors.New(message)
-				dialog.ShowError(error, parentWindow)
-				w.content = container.NewVBox()
-			default:
-				dialog.ShowError(errors.New(fmt.Sprint(res.StatusCode)), parentWindow)
+			RouterFor(parentWindow).Sync()
+		}
+	}
+
+	update := func() {
+		slots, err := api.GetRoleSlots(ctx, validatedLobbyToken)
+		if err != nil {
+			log.Err(err).Msg("failed getting available roles")
+			showRequestError(err, parentWindow)
+			return
+		}
+		slotsContainer.RemoveAll()
+
+		if appConfig.Role != sharedModels.NoRole {
+			releaseButton := widget.NewButton("Release role", func() {
+				log.Info().Msg("released role")
+				selectRole(sharedModels.NoRole)
+			})
+			slotsContainer.Add(widget.NewCard("Your role: "+roleNames[appConfig.Role], "Pick another role or release yours for someone else", releaseButton))
+		} else if slots.Full() {
+			slotsContainer.Add(widget.NewLabel("No roles available, lobby full"))
+			return
+		}
+
+		hiderButton := widget.NewButton("Hider", func() {
+			log.Info().Msg("chose hider role")
+			selectRole(sharedModels.Hider)
+		})
+		hiderStatus := "Free"
+		if !slots.Hider.Open() {
+			hiderButton.Disable()
+			hiderStatus = "Taken"
+			if len(slots.Hider.Players) > 0 {
+				hiderStatus += " by " + strings.Join(slots.Hider.Players, ", ")
 			}
 		}
+		if appConfig.Role == sharedModels.Hider {
+			hiderButton.Disable()
+		}
+		slotsContainer.Add(widget.NewCard("Hider", hiderStatus, hiderButton))
+
+		seekerButton := widget.NewButton("Join seeker team", func() {
+			log.Info().Msg("chose seeker role")
+			selectRole(sharedModels.Seeker)
+		})
+		if !slots.Seekers.Open() {
+			seekerButton.Disable()
+			seekerButton.SetText("Seeker team full")
+		}
+		if appConfig.Role == sharedModels.Seeker {
+			seekerButton.Disable()
+		}
+		teammates := "No seekers yet"
+		if len(slots.Seekers.Players) > 0 {
+			teammates = "Teammates: " + strings.Join(slots.Seekers.Players, ", ")
+		}
+		seekersTitle := "Seekers (" + fmt.Sprint(len(slots.Seekers.Players)) + "/" + fmt.Sprint(slots.Seekers.Max) + ")"
+		slotsContainer.Add(widget.NewCard(seekersTitle, teammates, seekerButton))
 	}
+	update()
+
+	// roles taken or released by others show up right away
+	events, err := api.Subscribe(ctx, client.EventLobby)
+	if err != nil {
+		log.Err(err).Msg("failed subscribing to lobby events")
+		return w
+	}
+	workers.Go(ctx, "role selection listener", func(ctx context.Context) {
+		for range events {
+			update()
+		}
+	})
 
 	return w
 }
@@ -122,51 +129,38 @@ func (w *RoleSelectionWidget) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(w.content)
 }
 
+// HandleRoleSelection takes the role in the lobby. A user who already holds a
+// role changes it instead, NoRole releases it.
 func HandleRoleSelection(env env.Env, validatedLobbyToken string, parentWindow fyne.Window, appConfig models.LoginInfo, role sharedModels.UserRole) error {
-	roleRequestStruct := sharedModels.UserRoleRequest{
-		Role: role,
+	var err error
+	if appConfig.Role == sharedModels.NoRole {
+		err = client.NewAPI(env).SelectRole(context.Background(), validatedLobbyToken, role)
+	} else {
+		err = client.NewAPI(env).ChangeRole(context.Background(), validatedLobbyToken, role)
 	}
-
-	roleJson, err := json.Marshal(roleRequestStruct)
-	roleReader := bytes.NewReader(roleJson)
 	if err != nil {
-		log.Err(err).Msg("")
-		dialog.ShowError(err, parentWindow)
+		log.Err(err).Msg("failed selecting role")
+		showRequestError(err, parentWindow)
 		return err
 	}
 
-	req, err := http.NewRequest("POST", env.Url+"/lobby/"+validatedLobbyToken+"/selectRole", roleReader)
+	err = saveRole(env, appConfig, role)
 	if err != nil {
-		log.Err(err).Msg(fmt.Sprint(err))
 		dialog.ShowError(err, parentWindow)
 		return err
-	} else {
-		req.Header.Add("Authorization", "Bearer "+appConfig.Token.String())
-	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Err(err).Msg(fmt.Sprint(err))
-		dialog.ShowError(err, parentWindow)
 	}
+	return nil
+}
 
-	switch res.StatusCode {
-	case http.StatusOK:
-		appConfig.Role = role
-		result := env.DB.Save(&appConfig)
-		if result.Error != nil {
-			log.Err(result.Error).Msg("failed to save roles in db")
-			dialog.ShowError(result.Error, parentWindow)
-			return err
-		}
-		return nil
-	case http.StatusConflict:
-		error := errors.New("This role has already been selected")
-		log.Err(err).Msg("")
-		dialog.ShowError(error, parentWindow)
-		return err
-	default:
-		error := errors.New("HTTP request failed with code " + fmt.Sprint(res.StatusCode))
-		dialog.ShowError(error, parentWindow)
-		return err
+// saveRole stores the role the server gave the user.
+func saveRole(env env.Env, appConfig models.LoginInfo, role sharedModels.UserRole) error {
+	// the lobby state only syncs the events relevant for the previous role
+	state.CloseAll()
+	appConfig.Role = role
+	result := env.DB.Save(&appConfig)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("failed to save roles in db")
+		return result.Error
 	}
+	return nil
 }
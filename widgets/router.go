@@ -0,0 +1,435 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/lobbies"
+	"github.com/jkulzer/fib-client/notifications"
+	"github.com/jkulzer/fib-client/router"
+	"github.com/jkulzer/fib-client/state"
+	"github.com/jkulzer/fib-client/workers"
+	"github.com/jkulzer/fib-server/sharedModels"
+)
+
+// Router shows the screens of the app in a window. All navigation goes
+// through it, so the shown screen always matches the state of the app.
+type Router struct {
+	parentWindow fyne.Window
+
+	mu      sync.Mutex
+	env     env.Env
+	history []router.Route
+	// stops the background work for the current server
+	stopServerWorkers context.CancelFunc
+	// changes the server rejected, replayed after the user logged in again
+	replays []replay
+	// whether the server rejected any request since the login dialog opened
+	rejected bool
+	// the login the rejected changes were sent with, they are dropped if the
+	// user logs in with another account
+	rejectedLoginID uint
+	// whether the login dialog for an expired session is shown
+	reauthenticating bool
+}
+
+// replay is a change the server rejected because of the session.
+type replay struct {
+	key  string
+	send func(ctx context.Context) error
+}
+
+var (
+	routersMu sync.Mutex
+	routers   = make(map[fyne.Window]*Router)
+)
+
+// NewRouter creates the router of the window and starts sending the queued
+// actions of the server and watching the session. It doesn't show anything
+// until Sync or Navigate is called.
+func NewRouter(env env.Env, parentWindow fyne.Window) *Router {
+	r := &Router{
+		env:          env,
+		parentWindow: parentWindow,
+	}
+	r.env.Reauthenticate = r.reauthenticate
+	watchForeground(fyne.CurrentApp())
+	watchLobbies()
+	r.runServerWorkers(r.env)
+	routersMu.Lock()
+	routers[parentWindow] = r
+	routersMu.Unlock()
+	return r
+}
+
+// Env returns the env of the server the app is connected to.
+func (r *Router) Env() env.Env {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.env
+}
+
+// SetServer connects the app to another server and shows the screen for the
+// login state on that server.
+func (r *Router) SetServer(serverUrl string) {
+	r.mu.Lock()
+	r.env.Url = serverUrl
+	r.history = nil
+	env := r.env
+	r.mu.Unlock()
+
+	log.Info().Msg("switched to server " + serverUrl)
+	state.CloseAll()
+	r.runServerWorkers(env)
+	r.Sync()
+}
+
+func (r *Router) runServerWorkers(env env.Env) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.mu.Lock()
+	if r.stopServerWorkers != nil {
+		r.stopServerWorkers()
+	}
+	r.stopServerWorkers = cancel
+	r.mu.Unlock()
+	workers.Go(ctx, "pending actions queue", client.NewAPI(env).RunQueue)
+	workers.Go(ctx, "session expiry check", func(ctx context.Context) {
+		checkSession(ctx, env, r)
+	})
+	workers.Go(ctx, "clock sync", func(ctx context.Context) {
+		syncClock(ctx, env)
+	})
+}
+
+// reauthenticate asks the user to log in again after the server rejected the
+// session. All changes rejected until then are replayed after the login, each
+// once. An empty key only asks for the login.
+func (r *Router) reauthenticate(key string, send func(ctx context.Context) error) {
+	r.mu.Lock()
+	if key != "" {
+		if !r.rejected {
+			loginInfo, err := helpers.GetAppConfig(r.env)
+			if err == nil {
+				r.rejectedLoginID = loginInfo.ID
+			}
+		}
+		r.rejected = true
+	}
+	if send != nil && !slices.ContainsFunc(r.replays, func(queued replay) bool { return queued.key == key }) {
+		r.replays = append(r.replays, replay{key: key, send: send})
+	}
+	if r.reauthenticating {
+		r.mu.Unlock()
+		return
+	}
+	r.reauthenticating = true
+	env := r.env
+	r.mu.Unlock()
+
+	showReauthDialog(env, r.parentWindow, r.reauthenticated)
+}
+
+// reauthenticated replays the rejected changes once the user logged in again
+// with the same account. If the user didn't log in, the session is gone for
+// good and the user is logged out.
+func (r *Router) reauthenticated(err error) {
+	r.mu.Lock()
+	replays := r.replays
+	rejected := r.rejected
+	rejectedLoginID := r.rejectedLoginID
+	r.replays = nil
+	r.rejected = false
+	r.reauthenticating = false
+	env := r.env
+	r.mu.Unlock()
+
+	if err != nil {
+		if !rejected {
+			return
+		}
+		log.Info().Msg("not logged in again, dropping " + fmt.Sprint(len(replays)) + " rejected requests")
+		err := helpers.DeleteAppConfig(env)
+		if err != nil {
+			log.Err(err).Msg("failed logging out")
+		}
+		r.Sync()
+		return
+	}
+
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err != nil || loginInfo.ID != rejectedLoginID {
+		log.Info().Msg("logged in with another account, dropping " + fmt.Sprint(len(replays)) + " rejected requests")
+		replays = nil
+	}
+	for _, replay := range replays {
+		err := replay.send(context.Background())
+		if err != nil {
+			showRequestError(err, r.parentWindow)
+		}
+	}
+	r.Sync()
+}
+
+// RouterFor returns the router of the window.
+func RouterFor(parentWindow fyne.Window) *Router {
+	routersMu.Lock()
+	defer routersMu.Unlock()
+	return routers[parentWindow]
+}
+
+// Current returns the route shown in the window.
+func (r *Router) Current() (router.Route, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.history) == 0 {
+		return router.Login, false
+	}
+	return r.history[len(r.history)-1], true
+}
+
+// Sync shows the screen the current state resolves to.
+func (r *Router) Sync() {
+	state, ok := r.state()
+	if !ok {
+		return
+	}
+	r.show(router.Resolve(state), state)
+}
+
+// Navigate shows the route if it is allowed in the current state and the
+// screen the state resolves to otherwise.
+func (r *Router) Navigate(route router.Route) {
+	state, ok := r.state()
+	if !ok {
+		return
+	}
+	if !router.Allowed(state, route) {
+		log.Info().Msg("route " + route.String() + " not allowed, showing " + router.Resolve(state).String())
+		route = router.Resolve(state)
+	}
+	r.show(route, state)
+}
+
+// Back shows the last screen before the current one that is still allowed in
+// the current state.
+func (r *Router) Back() {
+	state, ok := r.state()
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	route := router.Resolve(state)
+	for len(r.history) > 1 {
+		r.history = r.history[:len(r.history)-1]
+		previous := r.history[len(r.history)-1]
+		if router.Allowed(state, previous) {
+			route = previous
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	r.show(route, state)
+}
+
+// state returns the current state. If it can't be determined, the error is
+// shown and ok is false, unless nothing is shown yet; then the lobby screen
+// is the fallback.
+func (r *Router) state() (state router.State, ok bool) {
+	state, err := router.CurrentState(context.Background(), r.Env())
+	if err == nil {
+		return state, true
+	}
+	log.Err(err).Msg("failed getting app state for navigation")
+	showRequestError(err, r.parentWindow)
+	if _, shown := r.Current(); shown {
+		return state, false
+	}
+	state.LobbyToken = ""
+	return state, true
+}
+
+func (r *Router) show(route router.Route, state router.State) {
+	log.Info().Msg("showing route " + route.String())
+	r.mu.Lock()
+	if len(r.history) == 0 || r.history[len(r.history)-1] != route {
+		r.history = append(r.history, route)
+	}
+	r.mu.Unlock()
+
+	ShowScreen(r.parentWindow, func(ctx context.Context) fyne.CanvasObject {
+		if state.Role != sharedModels.NoRole && state.LobbyToken != "" {
+			r.followPhase(ctx, state.Phase)
+		}
+		if state.LobbyToken != "" && (state.Role == sharedModels.NoRole || state.Phase == sharedModels.PhaseBeforeStart) {
+			r.followMembership(ctx, state)
+		}
+		return r.build(ctx, route, state)
+	})
+}
+
+// followPhase shows the screen of the new phase once the game phase changes.
+func (r *Router) followPhase(ctx context.Context, phase sharedModels.GamePhase) {
+	events, err := client.NewAPI(r.Env()).Subscribe(ctx, client.EventPhase)
+	if err != nil {
+		log.Err(err).Msg("failed subscribing to phase events")
+		return
+	}
+	workers.Go(ctx, "router phase listener", func(ctx context.Context) {
+		for event := range events {
+			var phaseResponse sharedModels.PhaseResponse
+			err := event.Decode(&phaseResponse)
+			if err != nil {
+				log.Err(err).Msg("failed decoding phase event")
+				continue
+			}
+			if phaseResponse.Phase != phase {
+				log.Info().Msg("game phase changed to " + fmt.Sprint(phaseResponse.Phase))
+				r.Sync()
+				announcePhase(r.Env(), r.parentWindow, phaseResponse.Phase)
+				return
+			}
+		}
+	})
+}
+
+// followMembership shows the matching screen once the host changed the role
+// of the user or removed them from the lobby before the game started.
+func (r *Router) followMembership(ctx context.Context, state router.State) {
+	env := r.Env()
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err != nil {
+		log.Err(err).Msg("failed to get app config for the membership listener")
+		return
+	}
+	if loginInfo.Username == "" {
+		// logins saved before usernames were stored can't be found among the
+		// players, they would always look removed
+		log.Info().Msg("not following lobby membership, the username of the login is unknown")
+		return
+	}
+	events, err := client.NewAPI(env).Subscribe(ctx, client.EventLobby)
+	if err != nil {
+		log.Err(err).Msg("failed subscribing to lobby events")
+		return
+	}
+	workers.Go(ctx, "router membership listener", func(ctx context.Context) {
+		for event := range events {
+			var details client.LobbyDetails
+			err := event.Decode(&details)
+			if err != nil {
+				log.Err(err).Msg("failed decoding lobby event")
+				continue
+			}
+			// the user may have left or changed the role on their own meanwhile
+			current, err := helpers.GetAppConfig(env)
+			if err != nil || current.LobbyToken != state.LobbyToken {
+				return
+			}
+			player, ok := details.Player(loginInfo.Username)
+			if !ok {
+				log.Info().Msg("removed from lobby " + state.LobbyToken)
+				err := lobbies.Forget(env.DB, loginInfo.ID, state.LobbyToken)
+				if err != nil {
+					log.Err(err).Msg("failed forgetting lobby " + state.LobbyToken)
+				}
+				if switchLobby("", sharedModels.NoRole, r.parentWindow, env) == nil {
+					dialog.ShowInformation("Removed from lobby", "The host removed you from lobby "+state.LobbyToken+".", r.parentWindow)
+				}
+				return
+			}
+			if player.Role != current.Role {
+				log.Info().Msg("role changed to " + fmt.Sprint(player.Role) + " by the host")
+				err := saveRole(env, current, player.Role)
+				if err != nil {
+					dialog.ShowError(err, r.parentWindow)
+					return
+				}
+				r.Sync()
+				if player.Role == sharedModels.NoRole {
+					dialog.ShowInformation("Role released", "Your role was released. Pick a new one.", r.parentWindow)
+				} else {
+					dialog.ShowInformation("New role", "The host made you the "+roleNames[player.Role]+".", r.parentWindow)
+				}
+				return
+			}
+		}
+	})
+}
+
+func (r *Router) build(ctx context.Context, route router.Route, state router.State) fyne.CanvasObject {
+	env := r.Env()
+	parentWindow := r.parentWindow
+
+	var center fyne.CanvasObject
+	switch route {
+	case router.Login:
+		return GetLoginRegisterTabs(env, parentWindow)
+	case router.Lobby:
+		return NewLobbyWidget(env, parentWindow)
+	case router.Profile:
+		return NewProfileWidget(ctx, env, parentWindow)
+	case router.Accounts:
+		return NewAccountsWidget(env, parentWindow)
+	case router.AddAccount:
+		backButton := widget.NewButton("Back", func() {
+			RouterFor(parentWindow).Back()
+		})
+		return container.NewBorder(container.NewHBox(backButton), nil, nil, nil, GetLoginRegisterTabs(env, parentWindow))
+	case router.RoleSelect:
+		center = NewRoleSelectionWidget(ctx, env, parentWindow, state.LobbyToken)
+	case router.Readiness:
+		center = NewReadinessWidget(ctx, env, parentWindow)
+	case router.RunPhase:
+		if state.Role == sharedModels.Hider {
+			center = NewHiderRunPhaseWidget(ctx, env, parentWindow)
+		} else {
+			center = NewSeekerRunPhaseWidget(ctx, env, parentWindow)
+		}
+	case router.Narrowing:
+		if state.Role == sharedModels.Hider {
+			center = NewHiderNarrowingPhaseWidget(ctx, env, parentWindow)
+		} else {
+			center = NewSeekerNarrowingPhaseWidget(ctx, env, parentWindow)
+		}
+	case router.Endgame:
+		if state.Role == sharedModels.Hider {
+			center = NewHiderEndgameWidget(ctx, env, parentWindow)
+		} else {
+			center = NewSeekerEndgameWidget(ctx, env, parentWindow)
+		}
+	case router.Finished:
+		center = NewResultsWidget(ctx, env, parentWindow)
+	}
+	return NewGameFrameWidget(ctx, env, parentWindow, center)
+}
+
+// phaseAnnouncements are shown to both roles when the game enters the phase.
+var phaseAnnouncements = map[sharedModels.GamePhase]string{
+	sharedModels.PhaseRun:               "The game started. Hider, run!",
+	sharedModels.PhaseLocationNarrowing: "The hiding time is over. Seekers, start asking questions!",
+	sharedModels.PhaseEndgame:           "The endgame started: the seekers reached the hiding zone.",
+	sharedModels.PhaseFinished:          "The game is over: the hider was found.",
+}
+
+func announcePhase(env env.Env, parentWindow fyne.Window, phase sharedModels.GamePhase) {
+	announcement, ok := phaseAnnouncements[phase]
+	if !ok {
+		return
+	}
+	dialog.ShowInformation("New phase", announcement, parentWindow)
+	notify(env, notifications.KindPhase, "New phase", announcement)
+}
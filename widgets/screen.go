@@ -0,0 +1,32 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+
+	"context"
+	"sync"
+)
+
+var (
+	screensMu sync.Mutex
+	// cancels the context of the screen currently shown in a window
+	screens = make(map[fyne.Window]context.CancelFunc)
+)
+
+// ShowScreen replaces the content of the window with the screen built by
+// build. The context passed to build is cancelled as soon as the window shows
+// another screen, which stops all background work started by the screen.
+func ShowScreen(parentWindow fyne.Window, build func(ctx context.Context) fyne.CanvasObject) {
+	ctx, cancel := context.WithCancel(context.Background())
+	content := build(ctx)
+
+	screensMu.Lock()
+	previousCancel := screens[parentWindow]
+	screens[parentWindow] = cancel
+	screensMu.Unlock()
+
+	parentWindow.SetContent(content)
+	if previousCancel != nil {
+		previousCancel()
+	}
+}
@@ -12,49 +12,49 @@ import (
 	//
 	// "github.com/rs/zerolog/log"
 
-	"github.com/paulmach/orb/geojson"
-
-	"github.com/jkulzer/fib-client/client"
 	"github.com/jkulzer/fib-client/env"
 	"github.com/jkulzer/fib-client/mapWidget"
+	"github.com/jkulzer/fib-client/state"
 	// "github.com/jkulzer/fib-server/sharedModels"
+
+	"context"
 )
 
 type SeekerNarrowingPhaseWidget struct {
 	widget.BaseWidget
 	content *fyne.Container
-	fc      *geojson.FeatureCollection
 }
 
-func NewSeekerNarrowingPhaseWidget(env env.Env, parentWindow fyne.Window) *SeekerNarrowingPhaseWidget {
+func NewSeekerNarrowingPhaseWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *SeekerNarrowingPhaseWidget {
 	w := &SeekerNarrowingPhaseWidget{}
 	w.ExtendBaseWidget(w)
 
-	mapData, err := client.GetMapData(env, parentWindow)
+	w.content = container.NewStack()
+
+	store, err := state.For(env)
 	if err != nil {
 		dialog.ShowError(err, parentWindow)
 		return w
 	}
-
-	w.content = container.NewStack()
-
-	w.fc, err = geojson.UnmarshalFeatureCollection(mapData)
+	err = store.Refresh(ctx)
 	if err != nil {
-		dialog.ShowError(err, parentWindow)
+		showRequestError(err, parentWindow)
 		return w
 	}
 
-	mapWidgetInstance := mapWidget.NewMap(w.fc, env, &parentWindow)
-	historyWidgetInstance := NewHistoryWidget(env, parentWindow)
-	cursesWidgetInstance := NewCurseWidget(env, parentWindow)
+	mapWidgetInstance := mapWidget.NewMap(ctx, env, &parentWindow)
+	historyWidgetInstance := NewHistoryWidget(ctx, env, parentWindow)
+	cursesWidgetInstance := NewCurseWidget(ctx, env, parentWindow)
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Map", mapWidgetInstance),
-		container.NewTabItem("Questions", NewQuestionWidget(env, parentWindow, mapWidgetInstance, historyWidgetInstance)),
+		container.NewTabItem("Questions", NewQuestionWidget(ctx, env, parentWindow, mapWidgetInstance, historyWidgetInstance, false)),
 		container.NewTabItem("Curses", cursesWidgetInstance),
 		container.NewTabItem("History", historyWidgetInstance),
+		container.NewTabItem("Team", container.NewVScroll(NewTeamWidget(ctx, env, parentWindow))),
 	)
 	tabs.SetTabLocation(container.TabLocationBottom)
 	w.content = container.NewStack(tabs)
+	showTeamOnMap(ctx, env, mapWidgetInstance)
 
 	return w
 }
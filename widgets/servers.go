@@ -0,0 +1,156 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/models"
+	"github.com/jkulzer/fib-client/servers"
+)
+
+type ServerSelectWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewServerSelectWidget lets the user pick one of the saved servers, add a
+// new one or remove one. Every server keeps its own login.
+func NewServerSelectWidget(env env.Env, parentWindow fyne.Window) *ServerSelectWidget {
+	w := &ServerSelectWidget{}
+	w.ExtendBaseWidget(w)
+
+	profiles, err := servers.List(env.DB)
+	if err != nil {
+		log.Err(err).Msg("failed listing servers")
+		dialog.ShowError(err, parentWindow)
+	}
+
+	var current models.ServerProfile
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+		if profile.Url == env.Url {
+			current = profile
+		}
+	}
+
+	serverSelect := widget.NewSelect(names, nil)
+	if current.Url != "" {
+		serverSelect.SetSelected(current.Name)
+	}
+	serverSelect.OnChanged = func(name string) {
+		for _, profile := range profiles {
+			if profile.Name != name || profile.Url == env.Url {
+				continue
+			}
+			_, err := servers.Use(env.DB, "", profile.Url)
+			if err != nil {
+				log.Err(err).Msg("failed switching server")
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			RouterFor(parentWindow).SetServer(profile.Url)
+		}
+	}
+
+	addButton := widget.NewButton("Add server", func() {
+		showAddServerDialog(env, parentWindow)
+	})
+	testButton := widget.NewButton("Test connection", func() {
+		go func() {
+			info, err := client.NewAPI(env).Probe(context.Background())
+			if err != nil {
+				showRequestError(err, parentWindow)
+				return
+			}
+			version := info.Version
+			if info.Legacy {
+				version = "unknown, an older server"
+			}
+			dialog.ShowInformation("Server", "Connected to "+env.Url+"\nServer version: "+version, parentWindow)
+		}()
+	})
+	deleteButton := widget.NewButton("Delete", func() {
+		if current.Url == "" {
+			return
+		}
+		dialog.ShowConfirm("Delete server", "Delete "+current.Name+" and its login from this device?", func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			err := servers.Delete(env.DB, env.Secrets, current)
+			if err != nil {
+				log.Err(err).Msg("failed deleting server")
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			next, err := servers.LastOrDefault(env.DB)
+			if err != nil {
+				log.Err(err).Msg("failed saving default server")
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			RouterFor(parentWindow).SetServer(next.Url)
+		}, parentWindow)
+	})
+
+	w.content = container.NewBorder(nil, nil, widget.NewLabel("Server"), container.NewHBox(addButton, testButton, deleteButton), serverSelect)
+	return w
+}
+
+func (w *ServerSelectWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+// showAddServerDialog asks for a new server, checks that it is reachable and
+// switches to it.
+func showAddServerDialog(env env.Env, parentWindow fyne.Window) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Game night")
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://fib.example.com")
+	urlEntry.Validator = func(serverUrl string) error {
+		_, err := servers.NormalizeUrl(serverUrl)
+		return err
+	}
+
+	items := []*widget.FormItem{
+		{Text: "Name", Widget: nameEntry},
+		{Text: "Url", Widget: urlEntry},
+	}
+	dialog.ShowForm("Add server", "Add", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		go func() {
+			serverUrl, err := servers.NormalizeUrl(urlEntry.Text)
+			if err != nil {
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			serverEnv := env
+			serverEnv.Url = serverUrl
+			_, err = client.NewAPI(serverEnv).Probe(context.Background())
+			if err != nil {
+				log.Err(err).Msg("failed probing server " + serverUrl)
+				showRequestError(err, parentWindow)
+				return
+			}
+			profile, err := servers.Use(env.DB, nameEntry.Text, serverUrl)
+			if err != nil {
+				log.Err(err).Msg("failed saving server")
+				dialog.ShowError(err, parentWindow)
+				return
+			}
+			RouterFor(parentWindow).SetServer(profile.Url)
+		}()
+	}, parentWindow)
+}
@@ -0,0 +1,97 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+)
+
+const (
+	sessionCheckInterval = time.Minute
+	// sessions expiring sooner than this are refreshed
+	sessionRefreshBefore = 10 * time.Minute
+)
+
+// showReauthDialog asks for the password again after the session expired.
+// done is called with nil once the user is logged in again and with
+// client.ErrSessionExpired if they cancelled.
+func showReauthDialog(env env.Env, parentWindow fyne.Window, done func(error)) {
+	var username string
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err == nil {
+		username = loginInfo.Username
+	}
+
+	usernameEntry := widget.NewEntry()
+	usernameEntry.SetText(username)
+	if username != "" {
+		// rejected changes are replayed with the new session, which has to
+		// belong to the same account
+		usernameEntry.Disable()
+	}
+	passwordEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel("Your session expired or is about to expire.\nLog in again to continue.")),
+		{Text: "Username", Widget: usernameEntry},
+		{Text: "Password", Widget: passwordEntry},
+	}
+	dialog.ShowForm("Session expired", "Login", "Cancel", items, func(confirmed bool) {
+		if !confirmed {
+			done(client.ErrSessionExpired)
+			return
+		}
+		go func() {
+			err := logIn(env, usernameEntry.Text, passwordEntry.Text)
+			if err != nil {
+				log.Warn().Msg("failed logging in again: " + fmt.Sprint(err))
+				dialog.ShowError(err, parentWindow)
+				showReauthDialog(env, parentWindow, done)
+				return
+			}
+			done(nil)
+		}()
+	}, parentWindow)
+}
+
+// checkSession refreshes the session shortly before it expires. If the server
+// doesn't refresh it, the user is asked to log in again, once per session.
+func checkSession(ctx context.Context, env env.Env, r *Router) {
+	api := client.NewAPI(env)
+	ticker := time.NewTicker(sessionCheckInterval)
+	defer ticker.Stop()
+
+	var prompted time.Time
+	for {
+		loginInfo, err := helpers.GetAppConfig(env)
+		if err == nil && !loginInfo.Expiry.IsZero() && time.Until(loginInfo.Expiry) < sessionRefreshBefore {
+			session, err := api.RefreshSession(ctx)
+			if err == nil {
+				err = helpers.SaveSession(env, "", session)
+			}
+			if err == nil {
+				log.Info().Msg("refreshed session, it now expires at " + fmt.Sprint(session.Expiry))
+			} else if !prompted.Equal(loginInfo.Expiry) {
+				log.Info().Msg("failed refreshing session: " + fmt.Sprint(err))
+				prompted = loginInfo.Expiry
+				r.reauthenticate("", nil)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/jkulzer/fib-client/client"
+	"github.com/jkulzer/fib-client/env"
+	"github.com/jkulzer/fib-client/helpers"
+	"github.com/jkulzer/fib-client/mapWidget"
+	"github.com/jkulzer/fib-client/state"
+	"github.com/jkulzer/fib-client/workers"
+)
+
+// how often the ages of the locations and the cooldowns are updated
+const teamRefreshInterval = time.Second
+
+// seekerColors tell the seekers apart on the map, in the order of the team.
+var seekerColors = []color.Color{
+	color.RGBA{R: 231, G: 76, B: 60, A: 255},
+	color.RGBA{R: 142, G: 68, B: 173, A: 255},
+	color.RGBA{R: 230, G: 126, B: 34, A: 255},
+	color.RGBA{R: 22, G: 160, B: 133, A: 255},
+	color.RGBA{R: 52, G: 73, B: 94, A: 255},
+	color.RGBA{R: 241, G: 196, B: 15, A: 255},
+}
+
+func seekerColor(index int) color.Color {
+	return seekerColors[index%len(seekerColors)]
+}
+
+type TeamWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+}
+
+// NewTeamWidget lists the seekers of the team with where they were last seen
+// and the question cooldowns they share.
+func NewTeamWidget(ctx context.Context, env env.Env, parentWindow fyne.Window) *TeamWidget {
+	w := &TeamWidget{}
+	w.ExtendBaseWidget(w)
+	seekersList := container.NewVBox()
+	cooldownsLabel := widget.NewLabel("")
+	w.content = container.NewVBox(
+		widget.NewLabelWithStyle("Seeker team", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		seekersList,
+		cooldownsLabel,
+	)
+
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state")
+		dialog.ShowError(err, parentWindow)
+		return w
+	}
+	var username string
+	loginInfo, err := helpers.GetAppConfig(env)
+	if err == nil {
+		username = loginInfo.Username
+	}
+
+	update := func() {
+		team := store.Team()
+		seekersList.RemoveAll()
+		if len(team.Seekers) == 0 {
+			seekersList.Add(widget.NewLabel("No teammates known yet"))
+		}
+		for i, seeker := range team.Seekers {
+			swatch := canvas.NewRectangle(seekerColor(i))
+			swatch.SetMinSize(fyne.NewSize(12, 12))
+			name := seeker.Username
+			if name == username {
+				name += " (you)"
+			}
+			seekersList.Add(container.NewBorder(nil, nil, container.NewCenter(swatch), nil,
+				widget.NewLabel(name+": "+seekerLocationAge(seeker)),
+			))
+		}
+		cooldownsLabel.SetText(teamCooldowns(team))
+	}
+	update()
+	store.OnChange(ctx, state.TeamChanged, update)
+	workers.Go(ctx, "team refresh", func(ctx context.Context) {
+		ticker := time.NewTicker(teamRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	})
+
+	return w
+}
+
+func (w *TeamWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+func seekerLocationAge(seeker client.Seeker) string {
+	if seeker.Location == nil {
+		return "no location shared yet"
+	}
+	return "location from " + time.Since(seeker.LocatedAt).Truncate(time.Second).String() + " ago"
+}
+
+// teamCooldowns describes the question categories the team has to wait for.
+func teamCooldowns(team client.SeekerTeam) string {
+	text := ""
+	for _, category := range client.QuestionCategories {
+		left := team.CooldownLeft(category)
+		if left > 0 {
+			text += categoryNames[category] + ": wait " + formatCooldown(left) + "\n"
+		}
+	}
+	if text == "" {
+		return "All questions can be asked"
+	}
+	return "Team cooldowns:\n" + text
+}
+
+func formatCooldown(left time.Duration) string {
+	left = left.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+}
+
+// showTeamOnMap draws a marker for every seeker who shared their location,
+// until ctx is done.
+func showTeamOnMap(ctx context.Context, env env.Env, teamMap *mapWidget.Map) {
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state for the team markers")
+		return
+	}
+	update := func() {
+		var markers []mapWidget.Marker
+		for i, seeker := range store.Team().Seekers {
+			if seeker.Location == nil {
+				continue
+			}
+			markers = append(markers, mapWidget.Marker{Location: *seeker.Location, Color: seekerColor(i)})
+		}
+		teamMap.SetMarkers(markers)
+	}
+	update()
+	store.OnChange(ctx, state.TeamChanged, update)
+}
+
+// newCooldownLabels returns a label per question category showing how long
+// the team has to wait before asking such a question. A label is hidden while
+// its category can be asked.
+func newCooldownLabels(ctx context.Context, env env.Env) map[client.QuestionCategory]*widget.Label {
+	labels := make(map[client.QuestionCategory]*widget.Label)
+	for _, category := range client.QuestionCategories {
+		label := widget.NewLabel("")
+		label.Hide()
+		labels[category] = label
+	}
+	store, err := state.For(env)
+	if err != nil {
+		log.Err(err).Msg("failed getting lobby state for the question cooldowns")
+		return labels
+	}
+
+	update := func() {
+		team := store.Team()
+		for category, label := range labels {
+			left := team.CooldownLeft(category)
+			if left <= 0 {
+				label.Hide()
+				continue
+			}
+			label.SetText("Team cooldown, wait " + formatCooldown(left))
+			label.Show()
+		}
+	}
+	update()
+	workers.Go(ctx, "question cooldowns", func(ctx context.Context) {
+		ticker := time.NewTicker(teamRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	})
+	return labels
+}
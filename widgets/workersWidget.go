@@ -0,0 +1,65 @@
+package widgets
+
+import (
+	fyne "fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jkulzer/fib-client/workers"
+)
+
+// WorkersWidget lists the running background workers, to check that no
+// screen leaks goroutines.
+type WorkersWidget struct {
+	widget.BaseWidget
+	content *fyne.Container
+	list    *widget.Label
+}
+
+func NewWorkersWidget(ctx context.Context) *WorkersWidget {
+	w := &WorkersWidget{}
+	w.ExtendBaseWidget(w)
+	w.list = widget.NewLabel("")
+	w.content = container.NewStack(container.NewScroll(w.list))
+
+	workers.Go(ctx, "worker list", func(ctx context.Context) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			w.update()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	return w
+}
+
+func (w *WorkersWidget) update() {
+	active := workers.Active()
+	text := fmt.Sprint(len(active)) + " active workers\n"
+	for _, worker := range active {
+		text += fmt.Sprintf("\n#%d %s (running for %s)", worker.ID, worker.Name, time.Since(worker.Started).Truncate(time.Second))
+	}
+	w.list.SetText(text)
+}
+
+func (w *WorkersWidget) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+func showWorkersDialog(parentWindow fyne.Window) {
+	ctx, cancel := context.WithCancel(context.Background())
+	workersDialog := dialog.NewCustom("Background workers", "Close", NewWorkersWidget(ctx), parentWindow)
+	workersDialog.SetOnClosed(cancel)
+	workersDialog.Resize(fyne.NewSize(400, 500))
+	workersDialog.Show()
+}
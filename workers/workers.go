@@ -0,0 +1,56 @@
+// Package workers keeps track of the long-running goroutines of the app, so
+// leaking ones can be spotted in the debug view.
+package workers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Worker is a running background goroutine.
+type Worker struct {
+	ID      uint64
+	Name    string
+	Started time.Time
+}
+
+var (
+	mu     sync.Mutex
+	nextID uint64
+	active = make(map[uint64]Worker)
+)
+
+// Go runs f in a new goroutine and lists it as active until f returns. f has
+// to return once ctx is done.
+func Go(ctx context.Context, name string, f func(ctx context.Context)) {
+	mu.Lock()
+	nextID++
+	worker := Worker{ID: nextID, Name: name, Started: time.Now()}
+	active[worker.ID] = worker
+	mu.Unlock()
+
+	go func() {
+		defer func() {
+			mu.Lock()
+			delete(active, worker.ID)
+			mu.Unlock()
+		}()
+		f(ctx)
+	}()
+}
+
+// Active returns all running workers, oldest first.
+func Active() []Worker {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]Worker, 0, len(active))
+	for _, worker := range active {
+		list = append(list, worker)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}